@@ -1,7 +1,10 @@
 // Type definitions matching the Go backend models
 export interface ServiceInfo {
   name: string
-  state: 'stopped' | 'starting' | 'running' | 'stopping' | 'failed'
+  type?: 'simple' | 'oneshot'
+  instance_of?: string
+  state: 'stopped' | 'starting' | 'waiting' | 'running' | 'stopping' | 'failed' | 'backoff' | 'crash-loop' | 'completed'
+  desired_state: 'started' | 'stopped'
   pid: number
   command: string
   args: string[]
@@ -9,14 +12,70 @@ export interface ServiceInfo {
   env: Record<string, string>
   auto_start: boolean
   auto_restart: boolean
+  restart?: string
   restart_count: number
+  max_restarts?: number
+  next_restart_at?: string
+  next_planned_at?: string
+  planned_action?: string
   started_at: string | null
   stopped_at: string | null
   uptime: string
   cpu: number
   memory: number
   exit_code: number | null
+  exit_signal?: string
   error: string
+  health?: HealthInfo
+  limits?: ResourceLimit[]
+  scheduling?: SchedulingInfo
+  adopted?: boolean
+  schedule?: string
+  next_run_at?: string
+  runs?: JobRun[]
+  config_revision: number
+  run_revision?: number
+  restart_pending?: boolean // config changed, restart pending
+}
+
+export type ApplyMode = 'none' | 'restart' | 'reload-signal'
+
+export interface JobRun {
+  started_at: string
+  finished_at: string
+  duration: string
+  exit_code: number | null
+  exit_signal?: string
+  error?: string
+}
+
+export interface SchedulingInfo {
+  nice: number
+  ioprio: string
+  oom_score_adj: number
+  umask?: string
+}
+
+export interface ResourceLimit {
+  name: string
+  soft: number // -1 means unlimited
+  hard: number
+}
+
+export interface HealthResult {
+  healthy: boolean
+  output: string
+  duration: string
+  timestamp: string
+}
+
+export interface HealthInfo {
+  status: 'unknown' | 'healthy' | 'unhealthy'
+  consecutive_failures: number
+  consecutive_successes: number
+  last_output: string
+  last_check_at: string | null
+  results: HealthResult[]
 }
 
 export interface DaemonStatus {
@@ -28,6 +87,14 @@ export interface DaemonStatus {
   running_count: number
   stopped_count: number
   failed_count: number
+  backoff_count: number
+  crash_loop_count: number
+}
+
+export interface DaemonReload {
+  applied: string[]
+  restart_required: string[]
+  failed?: Record<string, string>
 }
 
 export interface LogEntry {
@@ -48,9 +115,22 @@ export interface ServiceConfig {
   max_restarts: number
   restart_delay: number
   stop_signal: string
+  reload_signal?: string
   stop_timeout: number
   log_file: string
   depends_on: string[]
+  // Other settings (user, limits, health_check, ...) are not edited by the
+  // GUI but must be sent back unchanged, see getServiceConfig.
+  [key: string]: unknown
+}
+
+// DependencyConflict lists why a service was not started (dependencies that
+// are not running) or not stopped or removed (running services that depend
+// on it).
+export interface DependencyConflict {
+  service: string
+  dependencies?: string[]
+  dependents?: string[]
 }
 
 // Wails runtime bindings - these are generated by Wails at build time
@@ -61,14 +141,16 @@ declare global {
       main: {
         ServiceBridge: {
           GetDaemonStatus(): Promise<DaemonStatus>
+          ReloadDaemon(): Promise<DaemonReload>
           ListServices(): Promise<ServiceInfo[]>
           GetService(name: string): Promise<ServiceInfo>
-          StartService(name: string): Promise<void>
-          StopService(name: string): Promise<void>
+          GetServiceConfig(name: string): Promise<ServiceConfig>
+          StartService(name: string, withDeps: boolean): Promise<DependencyConflict | null>
+          StopService(name: string, cascade: boolean): Promise<DependencyConflict | null>
           RestartService(name: string): Promise<void>
           CreateService(svc: ServiceConfig): Promise<void>
-          UpdateService(name: string, svc: ServiceConfig): Promise<void>
-          DeleteService(name: string): Promise<void>
+          UpdateService(name: string, svc: ServiceConfig, apply: ApplyMode): Promise<void>
+          DeleteService(name: string, cascade: boolean): Promise<DependencyConflict | null>
           GetLogs(name: string, n: number): Promise<LogEntry[]>
           GetDaemonAddress(): Promise<string>
           StartDaemon(): Promise<void>
@@ -113,11 +195,14 @@ async function httpPut<T>(path: string, body: unknown): Promise<T> {
   return json.data as T
 }
 
-async function httpDelete<T>(path: string): Promise<T> {
-  const resp = await fetch(`${httpBase}${path}`, { method: 'DELETE' })
+// httpAction performs a start, stop or delete request. A dependency conflict
+// (status 409) is returned instead of thrown.
+async function httpAction(method: 'POST' | 'DELETE', path: string): Promise<DependencyConflict | null> {
+  const resp = await fetch(`${httpBase}${path}`, { method })
   const json = await resp.json()
+  if (resp.status === 409 && json.data) return json.data as DependencyConflict
   if (!json.success) throw new Error(json.error)
-  return json.data as T
+  return null
 }
 
 export const api = {
@@ -126,6 +211,11 @@ export const api = {
     return httpGet<DaemonStatus>('/api/daemon/status')
   },
 
+  async reloadDaemon(): Promise<DaemonReload> {
+    if (isWails()) return window.go.main.ServiceBridge.ReloadDaemon()
+    return httpPost<DaemonReload>('/api/daemon/reload')
+  },
+
   async listServices(): Promise<ServiceInfo[]> {
     if (isWails()) return window.go.main.ServiceBridge.ListServices()
     return httpGet<ServiceInfo[]>('/api/services')
@@ -136,14 +226,18 @@ export const api = {
     return httpGet<ServiceInfo>(`/api/services/${name}`)
   },
 
-  async startService(name: string): Promise<void> {
-    if (isWails()) return window.go.main.ServiceBridge.StartService(name)
-    await httpPost(`/api/services/${name}/start`)
+  // startService starts a service, with its stopped dependencies if withDeps
+  // is set. Without it, the dependencies that are not running are returned.
+  async startService(name: string, withDeps = false): Promise<DependencyConflict | null> {
+    if (isWails()) return window.go.main.ServiceBridge.StartService(name, withDeps)
+    return httpAction('POST', `/api/services/${name}/start${withDeps ? '?deps=true' : ''}`)
   },
 
-  async stopService(name: string): Promise<void> {
-    if (isWails()) return window.go.main.ServiceBridge.StopService(name)
-    await httpPost(`/api/services/${name}/stop`)
+  // stopService stops a service, with its running dependents if cascade is
+  // set. Without it, the running dependents are returned.
+  async stopService(name: string, cascade = false): Promise<DependencyConflict | null> {
+    if (isWails()) return window.go.main.ServiceBridge.StopService(name, cascade)
+    return httpAction('POST', `/api/services/${name}/stop${cascade ? '?cascade=true' : ''}`)
   },
 
   async restartService(name: string): Promise<void> {
@@ -151,19 +245,24 @@ export const api = {
     await httpPost(`/api/services/${name}/restart`)
   },
 
+  async getServiceConfig(name: string): Promise<ServiceConfig> {
+    if (isWails()) return window.go.main.ServiceBridge.GetServiceConfig(name)
+    return httpGet<ServiceConfig>(`/api/services/${name}/config`)
+  },
+
   async createService(svc: ServiceConfig): Promise<void> {
     if (isWails()) return window.go.main.ServiceBridge.CreateService(svc)
     await httpPost('/api/services', svc)
   },
 
-  async updateService(name: string, svc: ServiceConfig): Promise<void> {
-    if (isWails()) return window.go.main.ServiceBridge.UpdateService(name, svc)
-    await httpPut(`/api/services/${name}`, svc)
+  async updateService(name: string, svc: ServiceConfig, apply: ApplyMode = 'none'): Promise<void> {
+    if (isWails()) return window.go.main.ServiceBridge.UpdateService(name, svc, apply)
+    await httpPut(`/api/services/${name}?apply=${apply}`, svc)
   },
 
-  async deleteService(name: string): Promise<void> {
-    if (isWails()) return window.go.main.ServiceBridge.DeleteService(name)
-    await httpDelete(`/api/services/${name}`)
+  async deleteService(name: string, cascade = false): Promise<DependencyConflict | null> {
+    if (isWails()) return window.go.main.ServiceBridge.DeleteService(name, cascade)
+    return httpAction('DELETE', `/api/services/${name}${cascade ? '?cascade=true' : ''}`)
   },
 
   async getLogs(name: string, n: number = 100): Promise<LogEntry[]> {
@@ -36,13 +36,23 @@ export const useServiceStore = defineStore('services', () => {
     }
   }
 
+  // startService starts a service. If some of its dependencies are not
+  // running, the user is asked whether to start them as well.
   async function startService(name: string) {
-    await api.startService(name)
+    const conflict = await api.startService(name)
+    if (conflict && confirm(`"${name}" depends on services that are not running: ${conflict.dependencies?.join(', ')}.\n\nStart them as well?`)) {
+      await api.startService(name, true)
+    }
     await fetchServices()
   }
 
+  // stopService stops a service. If running services depend on it, the user
+  // is asked whether to stop them as well.
   async function stopService(name: string) {
-    await api.stopService(name)
+    const conflict = await api.stopService(name)
+    if (conflict && confirmCascade(name, conflict.dependents, 'Stop them as well?')) {
+      await api.stopService(name, true)
+    }
     await fetchServices()
   }
 
@@ -51,9 +61,20 @@ export const useServiceStore = defineStore('services', () => {
     await fetchServices()
   }
 
-  async function deleteService(name: string) {
-    await api.deleteService(name)
+  // deleteService removes a service. If running services depend on it, the
+  // user is asked whether to stop them first. It reports whether the service
+  // was removed.
+  async function deleteService(name: string): Promise<boolean> {
+    let conflict = await api.deleteService(name)
+    if (conflict && confirmCascade(name, conflict.dependents, 'Stop them and remove the service?')) {
+      conflict = await api.deleteService(name, true)
+    }
     await fetchServices()
+    return !conflict
+  }
+
+  function confirmCascade(name: string, dependents: string[] = [], question: string) {
+    return confirm(`Running services depend on "${name}": ${dependents.join(', ')}.\n\n${question}`)
   }
 
   async function startDaemon() {
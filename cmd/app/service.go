@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -38,6 +39,11 @@ func (b *ServiceBridge) GetDaemonStatus() (*model.DaemonStatus, error) {
 	return b.client.DaemonStatus()
 }
 
+// ReloadDaemon makes the daemon reload its global configuration.
+func (b *ServiceBridge) ReloadDaemon() (*model.DaemonReload, error) {
+	return b.client.ReloadDaemon()
+}
+
 // ListServices returns all services.
 func (b *ServiceBridge) ListServices() ([]model.ServiceInfo, error) {
 	return b.client.ListServices()
@@ -48,14 +54,24 @@ func (b *ServiceBridge) GetService(name string) (*model.ServiceInfo, error) {
 	return b.client.GetService(name)
 }
 
-// StartService starts a service.
-func (b *ServiceBridge) StartService(name string) error {
-	return b.client.StartService(name)
+// StartService starts a service, and its stopped dependencies first if
+// withDeps is set. Otherwise, if some of its dependencies are not running,
+// nothing is started and they are returned so that the user can be asked
+// whether to start them too.
+func (b *ServiceBridge) StartService(name string, withDeps bool) (*model.DependencyError, error) {
+	err := b.client.StartService(name, model.StartOptions{WithDependencies: withDeps})
+	var depErr *model.DependencyError
+	if errors.As(err, &depErr) {
+		return depErr, nil
+	}
+	return nil, err
 }
 
-// StopService stops a service.
-func (b *ServiceBridge) StopService(name string) error {
-	return b.client.StopService(name)
+// StopService stops a service, and the running services that depend on it
+// first if cascade is set. Otherwise, if running services depend on it,
+// nothing is stopped and they are returned.
+func (b *ServiceBridge) StopService(name string, cascade bool) (*model.DependentsError, error) {
+	return dependents(b.client.StopService(name, model.StopOptions{Cascade: cascade}))
 }
 
 // RestartService restarts a service.
@@ -68,14 +84,31 @@ func (b *ServiceBridge) CreateService(svc config.ServiceConfig) error {
 	return b.client.CreateService(&svc)
 }
 
+// GetServiceConfig returns the stored configuration of a service.
+func (b *ServiceBridge) GetServiceConfig(name string) (*config.ServiceConfig, error) {
+	return b.client.GetServiceConfig(name)
+}
+
 // UpdateService updates a service.
-func (b *ServiceBridge) UpdateService(name string, svc config.ServiceConfig) error {
-	return b.client.UpdateService(name, &svc)
+func (b *ServiceBridge) UpdateService(name string, svc config.ServiceConfig, apply string) error {
+	return b.client.UpdateService(name, &svc, model.UpdateOptions{Apply: apply})
+}
+
+// DeleteService removes a service, stopping the running services that
+// depend on it first if cascade is set. Otherwise, if running services
+// depend on it, nothing is removed and they are returned.
+func (b *ServiceBridge) DeleteService(name string, cascade bool) (*model.DependentsError, error) {
+	return dependents(b.client.DeleteService(name, model.StopOptions{Cascade: cascade}))
 }
 
-// DeleteService removes a service.
-func (b *ServiceBridge) DeleteService(name string) error {
-	return b.client.DeleteService(name)
+// dependents returns the dependents a stop or removal was refused for as a
+// result rather than an error, so that the frontend can show them.
+func dependents(err error) (*model.DependentsError, error) {
+	var dependentsErr *model.DependentsError
+	if errors.As(err, &dependentsErr) {
+		return dependentsErr, nil
+	}
+	return nil, err
 }
 
 // GetLogs returns recent logs for a service.
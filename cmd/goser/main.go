@@ -1,10 +1,13 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"text/tabwriter"
@@ -51,6 +54,11 @@ func main() {
 			Short: "Check daemon status",
 			RunE:  daemonStatus,
 		},
+		&cobra.Command{
+			Use:   "reload",
+			Short: "Reload the global configuration",
+			RunE:  daemonReload,
+		},
 	)
 
 	// --- service commands ---
@@ -66,6 +74,8 @@ func main() {
 		Args:  cobra.ExactArgs(1),
 		RunE:  startService,
 	}
+	startCmd.Flags().BoolP("with-deps", "d", false, "Start stopped dependencies first without asking")
+	startCmd.Flags().Bool("transient", false, "Do not start the service again when the daemon restarts")
 
 	stopCmd := &cobra.Command{
 		Use:   "stop <name>",
@@ -73,6 +83,8 @@ func main() {
 		Args:  cobra.ExactArgs(1),
 		RunE:  stopService,
 	}
+	stopCmd.Flags().Bool("cascade", false, "Stop services that depend on this service first")
+	stopCmd.Flags().Bool("transient", false, "Do not keep the service stopped when the daemon restarts")
 
 	restartCmd := &cobra.Command{
 		Use:   "restart <name>",
@@ -81,6 +93,13 @@ func main() {
 		RunE:  restartService,
 	}
 
+	scaleCmd := &cobra.Command{
+		Use:   "scale <name> <instances>",
+		Short: "Set the number of instances of a multi-instance service",
+		Args:  cobra.ExactArgs(2),
+		RunE:  scaleService,
+	}
+
 	statusCmd := &cobra.Command{
 		Use:   "status <name>",
 		Short: "Get detailed service status",
@@ -95,12 +114,21 @@ func main() {
 		RunE:  addService,
 	}
 
+	updateCmd := &cobra.Command{
+		Use:   "update <yaml-file>",
+		Short: "Update a service from YAML file",
+		Args:  cobra.ExactArgs(1),
+		RunE:  updateService,
+	}
+	updateCmd.Flags().String("apply", model.ApplyNone, "Apply the change to running processes: none, restart or reload-signal")
+
 	removeCmd := &cobra.Command{
 		Use:   "remove <name>",
 		Short: "Remove a service",
 		Args:  cobra.ExactArgs(1),
 		RunE:  removeService,
 	}
+	removeCmd.Flags().Bool("cascade", false, "Stop services that depend on this service first")
 
 	enableCmd := &cobra.Command{
 		Use:   "enable <name>",
@@ -125,7 +153,25 @@ func main() {
 	logsCmd.Flags().IntP("lines", "n", 50, "Number of lines to show")
 	logsCmd.Flags().BoolP("follow", "f", false, "Follow log output (not yet implemented)")
 
-	rootCmd.AddCommand(daemonCmd, listCmd, startCmd, stopCmd, restartCmd, statusCmd, addCmd, removeCmd, enableCmd, disableCmd, logsCmd)
+	depsCmd := &cobra.Command{
+		Use:   "deps [name]",
+		Short: "Show the service dependency graph and boot order",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  showDeps,
+	}
+	depsCmd.Flags().StringP("format", "o", "tree", "Output format: tree, dot or json")
+
+	statsCmd := &cobra.Command{
+		Use:   "stats <name>",
+		Short: "Show resource usage history of a service",
+		Args:  cobra.ExactArgs(1),
+		RunE:  showStats,
+	}
+	statsCmd.Flags().String("since", "15m", "Show usage since a duration ago or an RFC 3339 time")
+	statsCmd.Flags().String("step", "", "Downsample to this interval (e.g. 1m)")
+	statsCmd.Flags().StringP("format", "o", "summary", "Output format: summary, table or json")
+
+	rootCmd.AddCommand(daemonCmd, listCmd, startCmd, stopCmd, restartCmd, scaleCmd, statusCmd, addCmd, updateCmd, removeCmd, enableCmd, disableCmd, logsCmd, depsCmd, statsCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
@@ -213,8 +259,37 @@ func daemonStatus(cmd *cobra.Command, args []string) error {
 	fmt.Println("Daemon Status:")
 	fmt.Printf("  Running:  yes\n")
 	fmt.Printf("  Uptime:   %s\n", status.Uptime)
-	fmt.Printf("  Services: %d total, %d running, %d stopped, %d failed\n",
-		status.ServiceCount, status.RunningCount, status.StoppedCount, status.FailedCount)
+	fmt.Printf("  Services: %d total, %d running, %d stopped, %d failed, %d backoff, %d crash-loop\n",
+		status.ServiceCount, status.RunningCount, status.StoppedCount, status.FailedCount,
+		status.BackoffCount, status.CrashLoopCount)
+	return nil
+}
+
+func daemonReload(cmd *cobra.Command, args []string) error {
+	result, err := cli.ReloadDaemon()
+	if err != nil {
+		return err
+	}
+
+	if len(result.Applied) == 0 && len(result.RestartRequired) == 0 && len(result.Failed) == 0 {
+		fmt.Println("Configuration reloaded, no daemon settings changed.")
+		return nil
+	}
+	fmt.Println("Configuration reloaded.")
+	if len(result.Applied) > 0 {
+		fmt.Printf("  Applied:          %s\n", strings.Join(result.Applied, ", "))
+	}
+	if len(result.RestartRequired) > 0 {
+		fmt.Printf("  Restart required: %s\n", strings.Join(result.RestartRequired, ", "))
+	}
+	keys := make([]string, 0, len(result.Failed))
+	for key := range result.Failed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Printf("  Failed:           %s: %s\n", key, result.Failed[key])
+	}
 	return nil
 }
 
@@ -232,7 +307,7 @@ func listServices(cmd *cobra.Command, args []string) error {
 	}
 
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "NAME\tSTATUS\tPID\tUPTIME\tRESTARTS\tCOMMAND")
+	fmt.Fprintln(w, "NAME\tSTATUS\tDESIRED\tPID\tUPTIME\tCPU\tMEM\tRESTARTS\tNEXT RUN\tLAST RUN\tCOMMAND")
 	for _, svc := range services {
 		pid := "-"
 		if svc.PID > 0 {
@@ -242,6 +317,18 @@ func listServices(cmd *cobra.Command, args []string) error {
 		if svc.Uptime != "" {
 			uptime = svc.Uptime
 		}
+		cpu, mem := "-", "-"
+		if svc.State == model.StateRunning {
+			cpu = fmt.Sprintf("%.1f%%", svc.CPU)
+			mem = formatBytes(svc.Memory)
+		}
+		nextRun, lastRun := "-", "-"
+		if svc.NextRunAt != nil {
+			nextRun = formatRunTime(*svc.NextRunAt)
+		}
+		if n := len(svc.Runs); n > 0 {
+			lastRun = formatRunTime(svc.Runs[n-1].StartedAt)
+		}
 		cmdStr := svc.Command
 		if len(svc.Args) > 0 {
 			cmdStr += " " + strings.Join(svc.Args, " ")
@@ -250,23 +337,43 @@ func listServices(cmd *cobra.Command, args []string) error {
 			cmdStr = cmdStr[:37] + "..."
 		}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n",
-			svc.Name, colorState(svc.State), pid, uptime, svc.RestartCount, cmdStr)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			svc.Name, colorState(svc.State), svc.DesiredState, pid, uptime, cpu, mem, svc.RestartCount, nextRun, lastRun, cmdStr)
 	}
 	w.Flush()
 	return nil
 }
 
 func startService(cmd *cobra.Command, args []string) error {
-	if err := cli.StartService(args[0]); err != nil {
+	withDeps, _ := cmd.Flags().GetBool("with-deps")
+	transient, _ := cmd.Flags().GetBool("transient")
+	err := cli.StartService(args[0], model.StartOptions{WithDependencies: withDeps, Transient: transient})
+
+	var depErr *model.DependencyError
+	if errors.As(err, &depErr) {
+		question := fmt.Sprintf("Service '%s' depends on stopped services: %s. Start them first?",
+			args[0], strings.Join(depErr.Dependencies, ", "))
+		if !confirm(question) {
+			return err
+		}
+		err = cli.StartService(args[0], model.StartOptions{WithDependencies: true, Transient: transient})
+	}
+	if err != nil {
 		return err
 	}
+
+	if info, err := cli.GetService(args[0]); err == nil && info.State == model.StateWaiting {
+		fmt.Printf("Service '%s' will start once its dependencies are ready.\n", args[0])
+		return nil
+	}
 	fmt.Printf("Service '%s' started.\n", args[0])
 	return nil
 }
 
 func stopService(cmd *cobra.Command, args []string) error {
-	if err := cli.StopService(args[0]); err != nil {
+	cascade, _ := cmd.Flags().GetBool("cascade")
+	transient, _ := cmd.Flags().GetBool("transient")
+	if err := cli.StopService(args[0], model.StopOptions{Cascade: cascade, Transient: transient}); err != nil {
 		return err
 	}
 	fmt.Printf("Service '%s' stopped.\n", args[0])
@@ -281,6 +388,18 @@ func restartService(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func scaleService(cmd *cobra.Command, args []string) error {
+	n, err := strconv.Atoi(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid number of instances: %s", args[1])
+	}
+	if err := cli.ScaleService(args[0], n); err != nil {
+		return err
+	}
+	fmt.Printf("Service '%s' scaled to %d instances.\n", args[0], n)
+	return nil
+}
+
 func serviceStatus(cmd *cobra.Command, args []string) error {
 	info, err := cli.GetService(args[0])
 	if err != nil {
@@ -289,16 +408,30 @@ func serviceStatus(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("Service: %s\n", info.Name)
 	fmt.Printf("  Status:      %s\n", colorState(info.State))
+	fmt.Printf("  Desired:     %s\n", info.DesiredState)
 	fmt.Printf("  Command:     %s %s\n", info.Command, strings.Join(info.Args, " "))
+	if info.Type == "oneshot" {
+		fmt.Println("  Type:        oneshot")
+	}
 	if info.WorkingDir != "" {
 		fmt.Printf("  Working Dir: %s\n", info.WorkingDir)
 	}
+	if info.User != "" || info.Group != "" {
+		fmt.Printf("  User:        %s\n", strings.TrimSuffix(info.User+":"+info.Group, ":"))
+	}
 	if info.PID > 0 {
 		fmt.Printf("  PID:         %d\n", info.PID)
+		if info.Adopted {
+			fmt.Println("  Adopted:     yes (started by a previous daemon)")
+		}
 	}
 	if info.Uptime != "" {
 		fmt.Printf("  Uptime:      %s\n", info.Uptime)
 	}
+	if info.State == model.StateRunning {
+		fmt.Printf("  CPU:         %.1f%%\n", info.CPU)
+		fmt.Printf("  Memory:      %s\n", formatBytes(info.Memory))
+	}
 	if info.StartedAt != nil {
 		fmt.Printf("  Started At:  %s\n", info.StartedAt.Format(time.RFC3339))
 	}
@@ -307,13 +440,88 @@ func serviceStatus(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Printf("  Auto Start:  %v\n", info.AutoStart)
 	fmt.Printf("  Auto Restart:%v\n", info.AutoRestart)
-	fmt.Printf("  Restarts:    %d\n", info.RestartCount)
-	if info.ExitCode != nil {
+	if info.Restart != "" {
+		fmt.Printf("  Restart:     %s\n", info.Restart)
+	}
+	if info.MaxRestarts > 0 {
+		fmt.Printf("  Restarts:    %d/%d\n", info.RestartCount, info.MaxRestarts)
+	} else {
+		fmt.Printf("  Restarts:    %d\n", info.RestartCount)
+	}
+	if info.NextRestartAt != nil {
+		wait := time.Until(*info.NextRestartAt).Round(time.Second)
+		if wait < 0 {
+			wait = 0
+		}
+		fmt.Printf("  Next Restart:restarting in %s (attempt %d/%d)\n", wait, info.RestartCount, info.MaxRestarts)
+	}
+	if info.RestartPending {
+		fmt.Printf("  Config:      revision %d, running revision %d (config changed, restart pending)\n", info.ConfigRevision, info.RunRevision)
+	}
+	if info.NextPlannedAt != nil {
+		fmt.Printf("  Planned:     %s at %s\n", info.PlannedAction, info.NextPlannedAt.Local().Format(time.RFC3339))
+	}
+	if info.ExitSignal != "" {
+		fmt.Printf("  Exit Signal: %s\n", info.ExitSignal)
+	} else if info.ExitCode != nil {
 		fmt.Printf("  Exit Code:   %d\n", *info.ExitCode)
 	}
 	if info.Error != "" {
 		fmt.Printf("  Error:       %s\n", info.Error)
 	}
+	if info.Schedule != "" {
+		fmt.Printf("  Schedule:    %s\n", info.Schedule)
+		if info.NextRunAt != nil {
+			fmt.Printf("  Next Run:    %s\n", info.NextRunAt.Format(time.RFC3339))
+		}
+		if len(info.Runs) > 0 {
+			fmt.Println("  Recent Runs:")
+			for _, r := range info.Runs {
+				result := "\033[32mok\033[0m  "
+				exit := "-"
+				switch {
+				case r.ExitSignal != "":
+					exit = r.ExitSignal
+					result = "\033[31mfail\033[0m"
+				case r.ExitCode != nil:
+					exit = strconv.Itoa(*r.ExitCode)
+					if *r.ExitCode != 0 {
+						result = "\033[31mfail\033[0m"
+					}
+				}
+				fmt.Printf("    %s  %s  exit %-7s  %s\n", r.StartedAt.Format("2006-01-02 15:04:05"), result, exit, r.Duration)
+			}
+		}
+	}
+	if len(info.Limits) > 0 {
+		fmt.Println("  Limits:")
+		for _, l := range info.Limits {
+			fmt.Printf("    %-7s soft %s, hard %s\n", l.Name, formatLimit(l.Name, l.Soft), formatLimit(l.Name, l.Hard))
+		}
+	}
+	if s := info.Scheduling; s != nil {
+		fmt.Printf("  Scheduling:  nice %d, ioprio %s, oom_score_adj %d", s.Nice, s.IOPrio, s.OOMScoreAdj)
+		if s.Umask != "" {
+			fmt.Printf(", umask %s", s.Umask)
+		}
+		fmt.Println()
+	}
+	if h := info.Health; h != nil {
+		fmt.Printf("  Health:      %s (%d consecutive failures)\n", colorHealth(h.Status), h.ConsecutiveFailures)
+		if h.LastOutput != "" {
+			fmt.Printf("  Last Probe:  %s\n", h.LastOutput)
+		}
+		if len(h.Results) > 0 {
+			fmt.Println("  Recent Probes:")
+			for _, r := range h.Results {
+				result := "\033[32mok\033[0m  "
+				if !r.Healthy {
+					result = "\033[31mfail\033[0m"
+				}
+				fmt.Printf("    %s  %s  %-6s  %s\n", r.Timestamp.Format("15:04:05"), result, r.Duration, r.Output)
+			}
+		}
+	}
 	return nil
 }
 
@@ -336,8 +544,33 @@ func addService(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func updateService(cmd *cobra.Command, args []string) error {
+	apply, _ := cmd.Flags().GetString("apply")
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+
+	var svc config.ServiceConfig
+	if err := yaml.Unmarshal(data, &svc); err != nil {
+		return fmt.Errorf("parse yaml: %w", err)
+	}
+
+	if err := cli.UpdateService(svc.Name, &svc, model.UpdateOptions{Apply: apply}); err != nil {
+		return err
+	}
+
+	fmt.Printf("Service '%s' updated.\n", svc.Name)
+	info, err := cli.GetService(svc.Name)
+	if err == nil && info.RestartPending {
+		fmt.Printf("Configuration changed, restart pending (goser restart %s or --apply restart).\n", svc.Name)
+	}
+	return nil
+}
+
 func removeService(cmd *cobra.Command, args []string) error {
-	if err := cli.DeleteService(args[0]); err != nil {
+	cascade, _ := cmd.Flags().GetBool("cascade")
+	if err := cli.DeleteService(args[0], model.StopOptions{Cascade: cascade}); err != nil {
 		return err
 	}
 	fmt.Printf("Service '%s' removed.\n", args[0])
@@ -345,20 +578,7 @@ func removeService(cmd *cobra.Command, args []string) error {
 }
 
 func enableService(cmd *cobra.Command, args []string) error {
-	info, err := cli.GetService(args[0])
-	if err != nil {
-		return err
-	}
-	svc := &config.ServiceConfig{
-		Name:        info.Name,
-		Command:     info.Command,
-		Args:        info.Args,
-		WorkingDir:  info.WorkingDir,
-		Env:         info.Env,
-		AutoStart:   true,
-		AutoRestart: info.AutoRestart,
-	}
-	if err := cli.UpdateService(args[0], svc); err != nil {
+	if err := setAutoStart(args[0], true); err != nil {
 		return err
 	}
 	fmt.Printf("Service '%s' enabled for auto-start.\n", args[0])
@@ -366,26 +586,24 @@ func enableService(cmd *cobra.Command, args []string) error {
 }
 
 func disableService(cmd *cobra.Command, args []string) error {
-	info, err := cli.GetService(args[0])
-	if err != nil {
-		return err
-	}
-	svc := &config.ServiceConfig{
-		Name:        info.Name,
-		Command:     info.Command,
-		Args:        info.Args,
-		WorkingDir:  info.WorkingDir,
-		Env:         info.Env,
-		AutoStart:   false,
-		AutoRestart: info.AutoRestart,
-	}
-	if err := cli.UpdateService(args[0], svc); err != nil {
+	if err := setAutoStart(args[0], false); err != nil {
 		return err
 	}
 	fmt.Printf("Service '%s' disabled for auto-start.\n", args[0])
 	return nil
 }
 
+// setAutoStart changes auto_start in the stored configuration of a service,
+// keeping all of its other settings.
+func setAutoStart(name string, enabled bool) error {
+	svc, err := cli.GetServiceConfig(name)
+	if err != nil {
+		return err
+	}
+	svc.AutoStart = enabled
+	return cli.UpdateService(name, svc, model.UpdateOptions{})
+}
+
 func viewLogs(cmd *cobra.Command, args []string) error {
 	n, _ := cmd.Flags().GetInt("lines")
 	logs, err := cli.GetLogs(args[0], n)
@@ -411,6 +629,318 @@ func viewLogs(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func showStats(cmd *cobra.Command, args []string) error {
+	since, _ := cmd.Flags().GetString("since")
+	step, _ := cmd.Flags().GetString("step")
+	format, _ := cmd.Flags().GetString("format")
+
+	metrics, err := cli.GetMetrics(args[0], since, step)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(metrics)
+	case "table", "summary":
+	default:
+		return fmt.Errorf("unknown format %q (expected summary, table or json)", format)
+	}
+
+	points := metrics.Points
+	if len(points) == 0 {
+		fmt.Println("No usage samples available.")
+		return nil
+	}
+
+	if format == "table" {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tCPU\tMEM\tTHREADS\tFDS")
+		for _, p := range points {
+			fmt.Fprintf(w, "%s\t%.1f%%\t%s\t%d\t%d\n",
+				p.Timestamp.Local().Format("15:04:05"), p.CPU, formatBytes(p.Memory), p.Threads, p.FDs)
+		}
+		w.Flush()
+		return nil
+	}
+
+	cpu := make([]float64, len(points))
+	mem := make([]float64, len(points))
+	threads := make([]float64, len(points))
+	fds := make([]float64, len(points))
+	for i, p := range points {
+		cpu[i] = p.CPU
+		mem[i] = float64(p.Memory)
+		threads[i] = float64(p.Threads)
+		fds[i] = float64(p.FDs)
+	}
+	last := points[len(points)-1]
+
+	fmt.Printf("Service: %s (%d points, step %s, %s - %s)\n", metrics.Service, len(points), metrics.Step,
+		points[0].Timestamp.Local().Format("15:04:05"), last.Timestamp.Local().Format("15:04:05"))
+	fmt.Printf("  CPU      %s  last %.1f%%, max %.1f%%\n", sparkline(cpu), last.CPU, maxOf(cpu))
+	fmt.Printf("  Memory   %s  last %s, max %s\n", sparkline(mem), formatBytes(last.Memory), formatBytes(uint64(maxOf(mem))))
+	fmt.Printf("  Threads  %s  last %d, max %.0f\n", sparkline(threads), last.Threads, maxOf(threads))
+	fmt.Printf("  FDs      %s  last %d, max %.0f\n", sparkline(fds), last.FDs, maxOf(fds))
+	return nil
+}
+
+// sparkline renders values as a row of block characters scaled between
+// their minimum and maximum, merging neighbouring values to fit 60 columns.
+func sparkline(values []float64) string {
+	const width = 60
+	blocks := []rune("▁▂▃▄▅▆▇█")
+
+	if len(values) > width {
+		merged := make([]float64, width)
+		for i := range merged {
+			lo, hi := i*len(values)/width, (i+1)*len(values)/width
+			merged[i] = maxOf(values[lo:hi])
+		}
+		values = merged
+	}
+
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = min(lo, v)
+		hi = max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		idx := 0
+		if hi > lo {
+			idx = int((v - lo) / (hi - lo) * float64(len(blocks)-1))
+		}
+		b.WriteRune(blocks[idx])
+	}
+	return b.String()
+}
+
+func maxOf(values []float64) float64 {
+	m := values[0]
+	for _, v := range values[1:] {
+		m = max(m, v)
+	}
+	return m
+}
+
+func showDeps(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	graph, err := cli.DependencyGraph()
+	if err != nil {
+		return err
+	}
+
+	nodes := make(map[string]model.GraphNode, len(graph.Nodes))
+	for _, n := range graph.Nodes {
+		nodes[n.Name] = n
+	}
+	if len(args) == 1 {
+		if _, ok := nodes[args[0]]; !ok {
+			return fmt.Errorf("service %s not found", args[0])
+		}
+		graph = subGraph(graph, nodes, args[0])
+	}
+
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(graph, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	case "dot":
+		fmt.Print(graph.DOT())
+		return nil
+	case "tree":
+	default:
+		return fmt.Errorf("unknown format %q (expected tree, dot or json)", format)
+	}
+
+	if len(graph.Nodes) == 0 {
+		fmt.Println("No services configured.")
+		return nil
+	}
+
+	var roots []string
+	if len(args) == 1 {
+		roots = []string{args[0]}
+	} else {
+		required := make(map[string]bool)
+		for _, n := range graph.Nodes {
+			for _, dep := range n.DependsOn {
+				required[dep] = true
+			}
+		}
+		for _, n := range graph.Nodes {
+			if !required[n.Name] {
+				roots = append(roots, n.Name)
+			}
+		}
+	}
+	for _, root := range roots {
+		printDepTree(nodes, root, "", "", map[string]bool{})
+	}
+
+	if len(args) == 1 {
+		var dependents []string
+		for _, n := range nodes {
+			for _, dep := range n.DependsOn {
+				if dep == args[0] {
+					dependents = append(dependents, n.Name)
+				}
+			}
+		}
+		if len(dependents) > 0 {
+			sort.Strings(dependents)
+			fmt.Printf("\nRequired by: %s\n", strings.Join(dependents, ", "))
+		}
+	}
+
+	fmt.Printf("\nBoot order: %s\n", strings.Join(graph.Order, " -> "))
+	for _, cycle := range graph.Cycles {
+		fmt.Printf("\033[31mDependency cycle: %s\033[0m\n", strings.Join(append(cycle, cycle[0]), " -> "))
+	}
+	return nil
+}
+
+// subGraph returns the part of the graph that name depends on, directly or
+// transitively, including name itself.
+func subGraph(graph *model.DependencyGraph, nodes map[string]model.GraphNode, name string) *model.DependencyGraph {
+	keep := make(map[string]bool)
+	var visit func(n string)
+	visit = func(n string) {
+		if keep[n] {
+			return
+		}
+		keep[n] = true
+		for _, dep := range nodes[n].DependsOn {
+			visit(dep)
+		}
+	}
+	visit(name)
+
+	result := &model.DependencyGraph{}
+	for _, n := range graph.Nodes {
+		if keep[n.Name] {
+			result.Nodes = append(result.Nodes, n)
+		}
+	}
+	for _, n := range graph.Order {
+		if keep[n] {
+			result.Order = append(result.Order, n)
+		}
+	}
+	for _, cycle := range graph.Cycles {
+		if keep[cycle[0]] {
+			result.Cycles = append(result.Cycles, cycle)
+		}
+	}
+	return result
+}
+
+// printDepTree prints a service and its dependencies as a tree.
+func printDepTree(nodes map[string]model.GraphNode, name, prefix, branch string, path map[string]bool) {
+	node := nodes[name]
+	if path[name] {
+		fmt.Printf("%s%s%s \033[31m(cycle)\033[0m\n", prefix, branch, name)
+		return
+	}
+	fmt.Printf("%s%s%s (%s)\n", prefix, branch, name, colorState(node.State))
+
+	path[name] = true
+	defer delete(path, name)
+
+	childPrefix := prefix
+	switch branch {
+	case "├── ":
+		childPrefix += "│   "
+	case "└── ":
+		childPrefix += "    "
+	}
+
+	children := len(node.DependsOn) + len(node.Missing)
+	for i, dep := range node.DependsOn {
+		b := "├── "
+		if i == children-1 {
+			b = "└── "
+		}
+		printDepTree(nodes, dep, childPrefix, b, path)
+	}
+	for i, dep := range node.Missing {
+		b := "├── "
+		if len(node.DependsOn)+i == children-1 {
+			b = "└── "
+		}
+		fmt.Printf("%s%s%s \033[31m(missing)\033[0m\n", childPrefix, b, dep)
+	}
+}
+
+// confirm asks a yes/no question on the terminal. It returns false when
+// stdin is not interactive.
+func confirm(question string) bool {
+	if fi, err := os.Stdin.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	fmt.Printf("%s [y/N] ", question)
+	var answer string
+	_, _ = fmt.Scanln(&answer)
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// colorHealth adds ANSI color to a health status for terminal display.
+func colorHealth(status model.HealthStatus) string {
+	switch status {
+	case model.HealthHealthy:
+		return "\033[32m" + string(status) + "\033[0m" // green
+	case model.HealthUnhealthy:
+		return "\033[31m" + string(status) + "\033[0m" // red
+	default:
+		return "\033[90m" + string(status) + "\033[0m" // gray
+	}
+}
+
+// formatRunTime formats a scheduled run time, omitting the date for today.
+func formatRunTime(t time.Time) string {
+	t = t.Local()
+	if t.Format("2006-01-02") == time.Now().Format("2006-01-02") {
+		return t.Format("15:04:05")
+	}
+	return t.Format("01-02 15:04")
+}
+
+// formatLimit formats a resource limit value for the given resource.
+func formatLimit(name string, v int64) string {
+	switch {
+	case v < 0:
+		return "unlimited"
+	case name == "core" || name == "as" || name == "data":
+		return formatBytes(uint64(v))
+	case name == "cpu":
+		return (time.Duration(v) * time.Second).String()
+	default:
+		return strconv.FormatInt(v, 10)
+	}
+}
+
+// formatBytes formats a byte count with a binary unit, e.g. "12.3 MiB".
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 // colorState adds ANSI color to state for terminal display.
 func colorState(state model.ServiceState) string {
 	switch state {
@@ -418,9 +948,11 @@ func colorState(state model.ServiceState) string {
 		return "\033[32m" + string(state) + "\033[0m" // green
 	case model.StateStopped:
 		return "\033[90m" + string(state) + "\033[0m" // gray
-	case model.StateFailed:
+	case model.StateCompleted:
+		return "\033[34m" + string(state) + "\033[0m" // blue
+	case model.StateFailed, model.StateCrashLoop:
 		return "\033[31m" + string(state) + "\033[0m" // red
-	case model.StateStarting, model.StateStopping:
+	case model.StateStarting, model.StateStopping, model.StateWaiting, model.StateBackoff:
 		return "\033[33m" + string(state) + "\033[0m" // yellow
 	default:
 		return string(state)
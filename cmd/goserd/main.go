@@ -14,7 +14,8 @@ import (
 
 // program implements the service.Interface for kardianos/service.
 type program struct {
-	srv *daemon.Server
+	srv  *daemon.Server
+	done chan struct{}
 }
 
 func (p *program) Start(s service.Service) error {
@@ -23,6 +24,7 @@ func (p *program) Start(s service.Service) error {
 }
 
 func (p *program) run() {
+	defer close(p.done)
 	if err := p.srv.Run(); err != nil {
 		logger.Get().Fatalf("daemon error: %v", err)
 	}
@@ -30,9 +32,9 @@ func (p *program) run() {
 
 func (p *program) Stop(s service.Service) error {
 	logger.Get().Info("service stop requested")
-	// The daemon.Server handles graceful shutdown via OS signals
-	proc, _ := os.FindProcess(os.Getpid())
-	_ = proc.Signal(os.Interrupt)
+	// Wait for the daemon so managed services are stopped before we exit.
+	p.srv.Shutdown()
+	<-p.done
 	return nil
 }
 
@@ -53,14 +55,10 @@ func main() {
 		fmt.Fprintf(os.Stderr, "failed to load global config: %v\n", err)
 		os.Exit(1)
 	}
-	if err := loader.LoadServices(); err != nil {
-		fmt.Fprintf(os.Stderr, "failed to load services: %v\n", err)
-		os.Exit(1)
-	}
 
 	// Initialize logger
 	cfg := loader.GetGlobal()
-	if err := logger.Init(cfg.Daemon.LogDir); err != nil {
+	if err := logger.Init(logger.FileConfigFor(&cfg.Daemon)); err != nil {
 		fmt.Fprintf(os.Stderr, "failed to init logger: %v\n", err)
 		os.Exit(1)
 	}
@@ -76,7 +74,7 @@ func main() {
 		Description: "GoSer non-blocking service manager daemon for managing background processes.",
 	}
 
-	prg := &program{srv: srv}
+	prg := &program{srv: srv, done: make(chan struct{})}
 	s, err := service.New(prg, svcConfig)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to create service: %v\n", err)
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/BAIGUANGMEI/goser/internal/config"
@@ -52,6 +53,22 @@ func (c *Client) DaemonStatus() (*model.DaemonStatus, error) {
 	return &status, nil
 }
 
+// ReloadDaemon makes the daemon reload its global configuration.
+func (c *Client) ReloadDaemon() (*model.DaemonReload, error) {
+	var resp model.APIResponse
+	if err := c.post("/api/daemon/reload", nil, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("reload failed: %s", resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	var result model.DaemonReload
+	_ = json.Unmarshal(data, &result)
+	return &result, nil
+}
+
 // --- Services ---
 
 // ListServices returns all services.
@@ -86,6 +103,25 @@ func (c *Client) GetService(name string) (*model.ServiceInfo, error) {
 	return &info, nil
 }
 
+// GetServiceConfig returns the stored configuration of a service, with all
+// of its fields, for a change to be sent back with UpdateService.
+func (c *Client) GetServiceConfig(name string) (*config.ServiceConfig, error) {
+	var resp model.APIResponse
+	if err := c.get("/api/services/"+name+"/config", &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("error: %s", resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	var svc config.ServiceConfig
+	if err := json.Unmarshal(data, &svc); err != nil {
+		return nil, fmt.Errorf("decode service config: %w", err)
+	}
+	return &svc, nil
+}
+
 // CreateService creates a new service.
 func (c *Client) CreateService(svc *config.ServiceConfig) error {
 	var resp model.APIResponse
@@ -98,10 +134,16 @@ func (c *Client) CreateService(svc *config.ServiceConfig) error {
 	return nil
 }
 
-// UpdateService updates a service configuration.
-func (c *Client) UpdateService(name string, svc *config.ServiceConfig) error {
+// UpdateService updates a service configuration. opts.Apply selects how
+// running processes pick up the change.
+func (c *Client) UpdateService(name string, svc *config.ServiceConfig, opts model.UpdateOptions) error {
+	path := "/api/services/" + name
+	if opts.Apply != "" {
+		path += "?apply=" + url.QueryEscape(opts.Apply)
+	}
+
 	var resp model.APIResponse
-	if err := c.put("/api/services/"+name, svc, &resp); err != nil {
+	if err := c.put(path, svc, &resp); err != nil {
 		return err
 	}
 	if !resp.Success {
@@ -110,40 +152,73 @@ func (c *Client) UpdateService(name string, svc *config.ServiceConfig) error {
 	return nil
 }
 
-// DeleteService removes a service.
-func (c *Client) DeleteService(name string) error {
+// DeleteService removes a service. If other running services depend on it
+// and opts.Cascade is false, a *model.DependentsError is returned.
+func (c *Client) DeleteService(name string, opts model.StopOptions) error {
+	path := "/api/services/" + name
+	if opts.Cascade {
+		path += "?cascade=true"
+	}
+
 	var resp model.APIResponse
-	if err := c.delete("/api/services/"+name, &resp); err != nil {
+	if err := c.delete(path, &resp); err != nil {
 		return err
 	}
 	if !resp.Success {
-		return fmt.Errorf("error: %s", resp.Error)
+		return actionError(resp)
 	}
 	return nil
 }
 
 // --- Service Actions ---
 
-// StartService starts a service.
-func (c *Client) StartService(name string) error {
+// StartService starts a service. If the service has dependencies that are
+// not running and opts.WithDependencies is false, a *model.DependencyError
+// is returned.
+func (c *Client) StartService(name string, opts model.StartOptions) error {
+	q := url.Values{}
+	if opts.WithDependencies {
+		q.Set("deps", "true")
+	}
+	if opts.Transient {
+		q.Set("transient", "true")
+	}
+	path := "/api/services/" + name + "/start"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+
 	var resp model.APIResponse
-	if err := c.post("/api/services/"+name+"/start", nil, &resp); err != nil {
+	if err := c.post(path, nil, &resp); err != nil {
 		return err
 	}
 	if !resp.Success {
-		return fmt.Errorf("error: %s", resp.Error)
+		return actionError(resp)
 	}
 	return nil
 }
 
-// StopService stops a service.
-func (c *Client) StopService(name string) error {
+// StopService stops a service. If other running services depend on it and
+// opts.Cascade is false, a *model.DependentsError is returned.
+func (c *Client) StopService(name string, opts model.StopOptions) error {
+	q := url.Values{}
+	if opts.Cascade {
+		q.Set("cascade", "true")
+	}
+	if opts.Transient {
+		q.Set("transient", "true")
+	}
+	path := "/api/services/" + name + "/stop"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+
 	var resp model.APIResponse
-	if err := c.post("/api/services/"+name+"/stop", nil, &resp); err != nil {
+	if err := c.post(path, nil, &resp); err != nil {
 		return err
 	}
 	if !resp.Success {
-		return fmt.Errorf("error: %s", resp.Error)
+		return actionError(resp)
 	}
 	return nil
 }
@@ -160,6 +235,18 @@ func (c *Client) RestartService(name string) error {
 	return nil
 }
 
+// ScaleService sets the number of instances of a multi-instance service.
+func (c *Client) ScaleService(name string, instances int) error {
+	var resp model.APIResponse
+	if err := c.put("/api/services/"+name+"/scale", model.ScaleRequest{Instances: instances}, &resp); err != nil {
+		return err
+	}
+	if !resp.Success {
+		return fmt.Errorf("error: %s", resp.Error)
+	}
+	return nil
+}
+
 // --- Logs ---
 
 // GetLogs returns recent logs for a service.
@@ -179,6 +266,73 @@ func (c *Client) GetLogs(name string, n int) ([]model.LogEntry, error) {
 	return logs, nil
 }
 
+// --- Metrics ---
+
+// GetMetrics returns the resource usage history of a service. since is a
+// duration (e.g. "15m") or RFC 3339 timestamp and step an optional
+// duration to downsample to; either may be empty.
+func (c *Client) GetMetrics(name, since, step string) (*model.ServiceMetrics, error) {
+	q := url.Values{}
+	if since != "" {
+		q.Set("since", since)
+	}
+	if step != "" {
+		q.Set("step", step)
+	}
+	path := fmt.Sprintf("/api/services/%s/metrics", name)
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+
+	var resp model.APIResponse
+	if err := c.get(path, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("error: %s", resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	var metrics model.ServiceMetrics
+	_ = json.Unmarshal(data, &metrics)
+	return &metrics, nil
+}
+
+// --- Dependency graph ---
+
+// DependencyGraph returns the service dependency graph.
+func (c *Client) DependencyGraph() (*model.DependencyGraph, error) {
+	var resp model.APIResponse
+	if err := c.get("/api/graph", &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, fmt.Errorf("error: %s", resp.Error)
+	}
+
+	data, _ := json.Marshal(resp.Data)
+	var graph model.DependencyGraph
+	_ = json.Unmarshal(data, &graph)
+	return &graph, nil
+}
+
+// actionError converts a failed service action response into an error,
+// restoring the typed dependency errors reported by the daemon.
+func actionError(resp model.APIResponse) error {
+	if resp.Data != nil {
+		data, _ := json.Marshal(resp.Data)
+		var depErr model.DependencyError
+		if json.Unmarshal(data, &depErr) == nil && len(depErr.Dependencies) > 0 {
+			return &depErr
+		}
+		var dependentsErr model.DependentsError
+		if json.Unmarshal(data, &dependentsErr) == nil && len(dependentsErr.Dependents) > 0 {
+			return &dependentsErr
+		}
+	}
+	return fmt.Errorf("error: %s", resp.Error)
+}
+
 // --- HTTP helpers ---
 
 func (c *Client) get(path string, result interface{}) error {
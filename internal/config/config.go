@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"time"
 )
 
@@ -13,11 +14,15 @@ type GlobalConfig struct {
 
 // DaemonConfig holds daemon-specific configuration.
 type DaemonConfig struct {
-	Listen       string `yaml:"listen"`
-	LogDir       string `yaml:"log_dir"`
-	PIDFile      string `yaml:"pid_file"`
-	MaxLogSize   string `yaml:"max_log_size"`
-	LogRetention int    `yaml:"log_retention"` // days
+	Listen              string        `yaml:"listen"`
+	LogDir              string        `yaml:"log_dir"`
+	PIDFile             string        `yaml:"pid_file"`
+	MaxLogSize          string        `yaml:"max_log_size"`
+	LogRetention        int           `yaml:"log_retention"`         // days
+	StatsInterval       time.Duration `yaml:"stats_interval"`        // CPU/memory sampling interval, 0 disables
+	MetricsRetention    time.Duration `yaml:"metrics_retention"`     // how long usage history is kept
+	MetricsResolution   time.Duration `yaml:"metrics_resolution"`    // time between stored history points
+	ConfigWatchInterval time.Duration `yaml:"config_watch_interval"` // how often service files are checked for changes, 0 disables
 }
 
 // DefaultGlobalConfig returns a GlobalConfig with sensible defaults.
@@ -25,22 +30,229 @@ func DefaultGlobalConfig() *GlobalConfig {
 	home := goserHome()
 	return &GlobalConfig{
 		Daemon: DaemonConfig{
-			Listen:       "127.0.0.1:9876",
-			LogDir:       filepath.Join(home, "logs"),
-			PIDFile:      filepath.Join(home, "goserd.pid"),
-			MaxLogSize:   "50MB",
-			LogRetention: 7,
+			Listen:              "127.0.0.1:9876",
+			LogDir:              filepath.Join(home, "logs"),
+			PIDFile:             filepath.Join(home, "goserd.pid"),
+			MaxLogSize:          "50MB",
+			LogRetention:        7,
+			StatsInterval:       5 * time.Second,
+			MetricsRetention:    time.Hour,
+			MetricsResolution:   10 * time.Second,
+			ConfigWatchInterval: 2 * time.Second,
 		},
 	}
 }
 
+// Validate checks the daemon configuration.
+func (d *DaemonConfig) Validate() error {
+	if d.Listen == "" {
+		return &ConfigError{Field: "daemon.listen", Message: "listen address is required"}
+	}
+	if d.LogDir == "" {
+		return &ConfigError{Field: "daemon.log_dir", Message: "log directory is required"}
+	}
+	if n, err := ParseSize(d.MaxLogSize); err != nil {
+		return &ConfigError{Field: "daemon.max_log_size", Message: err.Error()}
+	} else if n == Unlimited || n < 1<<20 {
+		return &ConfigError{Field: "daemon.max_log_size", Message: "max_log_size must be at least 1MB"}
+	}
+	if d.LogRetention < 0 {
+		return &ConfigError{Field: "daemon.log_retention", Message: "log_retention must not be negative"}
+	}
+	if d.StatsInterval < 0 || d.MetricsRetention < 0 || d.MetricsResolution < 0 || d.ConfigWatchInterval < 0 {
+		return &ConfigError{Field: "daemon", Message: "intervals must not be negative"}
+	}
+	return nil
+}
+
+// ValidateService checks the settings of a service that depend on the
+// daemon: a watchdog acts on the sampled resource usage, so it needs
+// sampling to be enabled and supported.
+func (d *DaemonConfig) ValidateService(svc *ServiceConfig) error {
+	if svc.Watchdog == nil {
+		return nil
+	}
+	if runtime.GOOS != "linux" {
+		return &ConfigError{Field: "watchdog", Message: "watchdog is only supported on Linux"}
+	}
+	if d.StatsInterval == 0 {
+		return &ConfigError{Field: "watchdog", Message: "watchdog needs resource sampling, which is disabled by daemon.stats_interval: 0"}
+	}
+	return nil
+}
+
+// LogRotation returns the size in megabytes at which log files are rotated
+// and the number of days rotated files are kept (0 keeps them forever).
+func (d *DaemonConfig) LogRotation() (maxSizeMB, maxAgeDays int) {
+	n, _ := ParseSize(d.MaxLogSize)
+	return int(n >> 20), d.LogRetention
+}
+
+// Changes returns the keys of the settings that differ between d and other.
+func (d *DaemonConfig) Changes(other *DaemonConfig) []string {
+	var keys []string
+	diff := func(key string, changed bool) {
+		if changed {
+			keys = append(keys, key)
+		}
+	}
+	diff("listen", d.Listen != other.Listen)
+	diff("log_dir", d.LogDir != other.LogDir)
+	diff("pid_file", d.PIDFile != other.PIDFile)
+	diff("max_log_size", d.MaxLogSize != other.MaxLogSize)
+	diff("log_retention", d.LogRetention != other.LogRetention)
+	diff("stats_interval", d.StatsInterval != other.StatsInterval)
+	diff("metrics_retention", d.MetricsRetention != other.MetricsRetention)
+	diff("metrics_resolution", d.MetricsResolution != other.MetricsResolution)
+	diff("config_watch_interval", d.ConfigWatchInterval != other.ConfigWatchInterval)
+	return keys
+}
+
 // HealthCheckConfig configures a health check for a service.
 type HealthCheckConfig struct {
-	Type     string        `yaml:"type"`     // http | tcp | command
-	Endpoint string        `yaml:"endpoint"` // URL or address
-	Command  string        `yaml:"command"`  // command to execute
-	Interval time.Duration `yaml:"interval"`
-	Timeout  time.Duration `yaml:"timeout"`
+	Type     string        `yaml:"type"     json:"type"`     // http | tcp | command
+	Endpoint string        `yaml:"endpoint" json:"endpoint"` // URL or address
+	Command  string        `yaml:"command"  json:"command"`  // command to execute
+	Interval time.Duration `yaml:"interval" json:"interval"`
+	Timeout  time.Duration `yaml:"timeout"  json:"timeout"`
+
+	FailureThreshold int           `yaml:"failure_threshold" json:"failure_threshold"` // failures before unhealthy
+	SuccessThreshold int           `yaml:"success_threshold" json:"success_threshold"` // successes before healthy
+	StartPeriod      time.Duration `yaml:"start_period"      json:"start_period"`      // grace period after start
+	OnUnhealthy      string        `yaml:"on_unhealthy"      json:"on_unhealthy"`      // none | restart | stop
+}
+
+// Validate checks the health check configuration and applies defaults.
+func (h *HealthCheckConfig) Validate() error {
+	switch h.Type {
+	case "http", "tcp":
+		if h.Endpoint == "" {
+			return &ConfigError{Field: "health_check.endpoint", Message: "endpoint is required for " + h.Type + " checks"}
+		}
+	case "command":
+		if h.Command == "" {
+			return &ConfigError{Field: "health_check.command", Message: "command is required for command checks"}
+		}
+	default:
+		return &ConfigError{Field: "health_check.type", Message: "type must be one of http, tcp, command"}
+	}
+	if h.Interval == 0 {
+		h.Interval = 30 * time.Second
+	}
+	if h.Timeout == 0 {
+		h.Timeout = 5 * time.Second
+	}
+	if h.FailureThreshold == 0 {
+		h.FailureThreshold = 3
+	}
+	if h.SuccessThreshold == 0 {
+		h.SuccessThreshold = 1
+	}
+	if h.FailureThreshold < 0 || h.SuccessThreshold < 0 || h.StartPeriod < 0 {
+		return &ConfigError{Field: "health_check", Message: "thresholds and start_period must not be negative"}
+	}
+	switch h.OnUnhealthy {
+	case "":
+		h.OnUnhealthy = "none"
+	case "none", "restart", "stop":
+	default:
+		return &ConfigError{Field: "health_check.on_unhealthy", Message: "on_unhealthy must be one of none, restart, stop"}
+	}
+	return nil
+}
+
+// WatchdogConfig configures actions taken when a service's process tree
+// uses too much memory or CPU for a sustained period.
+type WatchdogConfig struct {
+	MaxMemory     string        `yaml:"max_memory"      json:"max_memory,omitempty"`      // e.g. 512MB
+	MaxCPUPercent float64       `yaml:"max_cpu_percent" json:"max_cpu_percent,omitempty"` // percent of one CPU
+	For           time.Duration `yaml:"for"             json:"for"`                       // how long a limit must be exceeded
+	Action        string        `yaml:"action"          json:"action"`                    // restart | stop | event
+}
+
+// Validate checks the watchdog configuration and applies defaults.
+func (w *WatchdogConfig) Validate() error {
+	if w.MaxMemory == "" && w.MaxCPUPercent == 0 {
+		return &ConfigError{Field: "watchdog", Message: "max_memory or max_cpu_percent is required"}
+	}
+	if w.MaxMemory != "" {
+		if _, err := ParseSize(w.MaxMemory); err != nil {
+			return &ConfigError{Field: "watchdog.max_memory", Message: err.Error()}
+		}
+	}
+	if w.MaxCPUPercent < 0 || w.For < 0 {
+		return &ConfigError{Field: "watchdog", Message: "max_cpu_percent and for must not be negative"}
+	}
+	if w.For == 0 {
+		w.For = time.Minute
+	}
+	switch w.Action {
+	case "":
+		w.Action = "restart"
+	case "restart", "stop", "event":
+	default:
+		return &ConfigError{Field: "watchdog.action", Message: "action must be one of restart, stop, event"}
+	}
+	return nil
+}
+
+// MemoryLimit returns max_memory in bytes, or 0 if it is not set.
+func (w *WatchdogConfig) MemoryLimit() uint64 {
+	if w.MaxMemory == "" {
+		return 0
+	}
+	n, _ := ParseSize(w.MaxMemory)
+	return n
+}
+
+// IOPrioConfig sets the I/O scheduling class and priority of a service.
+type IOPrioConfig struct {
+	Class string `yaml:"class" json:"class"` // realtime | best-effort | idle
+	Level int    `yaml:"level" json:"level"` // 0 (highest) to 7 (lowest); ignored for idle
+}
+
+// Validate checks the I/O priority.
+func (p *IOPrioConfig) Validate() error {
+	switch p.Class {
+	case "":
+		p.Class = "best-effort"
+	case "realtime", "best-effort", "idle":
+	default:
+		return &ConfigError{Field: "ioprio.class", Message: "class must be one of realtime, best-effort, idle"}
+	}
+	if p.Level < 0 || p.Level > 7 {
+		return &ConfigError{Field: "ioprio.level", Message: "level must be between 0 and 7"}
+	}
+	return nil
+}
+
+// RestartPolicyConfig controls how the delay between automatic restarts grows.
+type RestartPolicyConfig struct {
+	Backoff    string        `yaml:"backoff"     json:"backoff"`               // fixed | exponential
+	MaxDelay   time.Duration `yaml:"max_delay"   json:"max_delay"`             // cap for exponential backoff
+	Jitter     float64       `yaml:"jitter"      json:"jitter,omitempty"`      // random spread as a fraction of the delay (0-1)
+	ResetAfter time.Duration `yaml:"reset_after" json:"reset_after,omitempty"` // uptime after which the restart counter is reset
+}
+
+// Validate checks the restart policy and applies defaults.
+func (r *RestartPolicyConfig) Validate() error {
+	switch r.Backoff {
+	case "":
+		r.Backoff = "fixed"
+	case "fixed", "exponential":
+	default:
+		return &ConfigError{Field: "restart_policy.backoff", Message: "backoff must be one of fixed, exponential"}
+	}
+	if r.MaxDelay == 0 {
+		r.MaxDelay = 5 * time.Minute
+	}
+	if r.MaxDelay < 0 || r.ResetAfter < 0 {
+		return &ConfigError{Field: "restart_policy", Message: "max_delay and reset_after must not be negative"}
+	}
+	if r.Jitter < 0 || r.Jitter > 1 {
+		return &ConfigError{Field: "restart_policy.jitter", Message: "jitter must be between 0 and 1"}
+	}
+	return nil
 }
 
 // GoserHome returns the path to the goser configuration directory.
@@ -61,11 +273,18 @@ func ServicesDir() string {
 	return filepath.Join(goserHome(), "services")
 }
 
-// EnsureDirs creates the goser home and services directories if they don't exist.
+// StateDir returns the path to the directory holding the runtime state of
+// running services.
+func StateDir() string {
+	return filepath.Join(goserHome(), "state")
+}
+
+// EnsureDirs creates the goser home, services and state directories if they don't exist.
 func EnsureDirs() error {
 	dirs := []string{
 		goserHome(),
 		ServicesDir(),
+		StateDir(),
 		filepath.Join(goserHome(), "logs"),
 	}
 	for _, d := range dirs {
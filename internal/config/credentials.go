@@ -0,0 +1,15 @@
+package config
+
+// Credentials identifies the user and groups a service runs as.
+type Credentials struct {
+	UID      uint32
+	GID      uint32
+	Groups   []uint32
+	Username string // empty if only the group is changed
+	HomeDir  string
+}
+
+// RunsAsOtherUser reports whether user, group or supplementary_groups is set.
+func (c *ServiceConfig) RunsAsOtherUser() bool {
+	return c.User != "" || c.Group != "" || len(c.SupplementaryGroups) > 0
+}
@@ -0,0 +1,98 @@
+//go:build !windows
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+)
+
+// Credentials looks up the user, group and supplementary groups of the
+// service. It returns nil if none of them is set. A user's own group
+// memberships are included in the supplementary groups.
+func (c *ServiceConfig) Credentials() (*Credentials, error) {
+	if !c.RunsAsOtherUser() {
+		return nil, nil
+	}
+
+	cred := &Credentials{UID: uint32(os.Geteuid()), GID: uint32(os.Getegid())}
+	if c.User != "" {
+		u, err := lookupUser(c.User)
+		if err != nil {
+			return nil, err
+		}
+		uid, _ := strconv.ParseUint(u.Uid, 10, 32)
+		gid, _ := strconv.ParseUint(u.Gid, 10, 32)
+		cred.UID, cred.GID = uint32(uid), uint32(gid)
+		cred.Username, cred.HomeDir = u.Username, u.HomeDir
+
+		if ids, err := u.GroupIds(); err == nil {
+			for _, id := range ids {
+				if g, err := strconv.ParseUint(id, 10, 32); err == nil && uint32(g) != cred.GID {
+					cred.Groups = append(cred.Groups, uint32(g))
+				}
+			}
+		}
+	}
+	if c.Group != "" {
+		gid, err := lookupGroup(c.Group)
+		if err != nil {
+			return nil, err
+		}
+		cred.GID = gid
+	}
+	for _, name := range c.SupplementaryGroups {
+		gid, err := lookupGroup(name)
+		if err != nil {
+			return nil, err
+		}
+		cred.Groups = append(cred.Groups, gid)
+	}
+	return cred, nil
+}
+
+// validateCredentials checks that the user and groups exist and that the
+// daemon is privileged enough to switch to them.
+func (c *ServiceConfig) validateCredentials() error {
+	cred, err := c.Credentials()
+	if err != nil {
+		return &ConfigError{Field: "user", Message: err.Error()}
+	}
+	if cred == nil || os.Geteuid() == 0 {
+		return nil
+	}
+	if cred.UID != uint32(os.Geteuid()) || cred.GID != uint32(os.Getegid()) || len(c.SupplementaryGroups) > 0 {
+		return &ConfigError{Field: "user", Message: "goserd must run as root to run services as another user or group"}
+	}
+	return nil
+}
+
+func lookupUser(name string) (*user.User, error) {
+	if _, err := strconv.Atoi(name); err == nil {
+		if u, err := user.LookupId(name); err == nil {
+			return u, nil
+		}
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown user %q", name)
+	}
+	return u, nil
+}
+
+func lookupGroup(name string) (uint32, error) {
+	if id, err := strconv.ParseUint(name, 10, 32); err == nil {
+		return uint32(id), nil
+	}
+	g, err := user.LookupGroup(name)
+	if err != nil {
+		return 0, fmt.Errorf("unknown group %q", name)
+	}
+	id, err := strconv.ParseUint(g.Gid, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("group %q has a non-numeric ID %s", name, g.Gid)
+	}
+	return uint32(id), nil
+}
@@ -0,0 +1,20 @@
+//go:build windows
+
+package config
+
+import "errors"
+
+// Credentials is not supported on Windows and fails if a user or group is set.
+func (c *ServiceConfig) Credentials() (*Credentials, error) {
+	if !c.RunsAsOtherUser() {
+		return nil, nil
+	}
+	return nil, errors.New("running services as another user is not supported on Windows")
+}
+
+func (c *ServiceConfig) validateCredentials() error {
+	if _, err := c.Credentials(); err != nil {
+		return &ConfigError{Field: "user", Message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// DependencyOrder returns the service names sorted so that every service
+// comes after its dependencies. Services that are part of a dependency
+// cycle are omitted; dependencies on unknown services are ignored.
+func DependencyOrder(services map[string]*ServiceConfig) []string {
+	dependents := make(map[string][]string)
+	inDegree := make(map[string]int)
+	for name := range services {
+		inDegree[name] = 0
+	}
+	for name, svc := range services {
+		for _, dep := range uniqueDeps(svc.DependsOn) {
+			if _, ok := services[dep]; !ok {
+				continue
+			}
+			dependents[dep] = append(dependents[dep], name)
+			inDegree[name]++
+		}
+	}
+
+	// Kahn's algorithm, picking names alphabetically for a stable order
+	var queue []string
+	for name, deg := range inDegree {
+		if deg == 0 {
+			queue = append(queue, name)
+		}
+	}
+	sort.Strings(queue)
+
+	var order []string
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		order = append(order, node)
+
+		next := dependents[node]
+		sort.Strings(next)
+		for _, d := range next {
+			inDegree[d]--
+			if inDegree[d] == 0 {
+				queue = append(queue, d)
+			}
+		}
+	}
+	return order
+}
+
+// DependencyCycles returns every dependency cycle among the services. Each
+// cycle starts at its alphabetically smallest member.
+func DependencyCycles(services map[string]*ServiceConfig) [][]string {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make(map[string]int)
+	seen := make(map[string]bool)
+	var cycles [][]string
+	var stack []string
+
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = inProgress
+		stack = append(stack, name)
+		for _, dep := range uniqueDeps(services[name].DependsOn) {
+			if _, ok := services[dep]; !ok {
+				continue
+			}
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case inProgress:
+				// Found a back edge: the cycle is the stack from dep onwards
+				var cycle []string
+				for i := len(stack) - 1; i >= 0; i-- {
+					if stack[i] == dep {
+						cycle = append(cycle, stack[i:]...)
+						break
+					}
+				}
+				cycle = rotateCycle(cycle)
+				key := strings.Join(cycle, "\x00")
+				if !seen[key] {
+					seen[key] = true
+					cycles = append(cycles, cycle)
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = done
+	}
+
+	for _, name := range names {
+		if state[name] == unvisited {
+			visit(name)
+		}
+	}
+	return cycles
+}
+
+// MissingDependencies returns, per service, the dependencies that do not
+// name a configured service.
+func MissingDependencies(services map[string]*ServiceConfig) map[string][]string {
+	missing := make(map[string][]string)
+	for name, svc := range services {
+		for _, dep := range uniqueDeps(svc.DependsOn) {
+			if _, ok := services[dep]; !ok {
+				missing[name] = append(missing[name], dep)
+			}
+		}
+	}
+	return missing
+}
+
+// ValidateDependencies checks that every depends_on entry names a configured
+// service and that there are no dependency cycles.
+func ValidateDependencies(services map[string]*ServiceConfig) error {
+	var problems []string
+
+	missing := MissingDependencies(services)
+	names := make([]string, 0, len(missing))
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		problems = append(problems, "service "+name+" depends on unknown service(s) "+strings.Join(missing[name], ", "))
+	}
+
+	for _, cycle := range DependencyCycles(services) {
+		problems = append(problems, "dependency cycle "+strings.Join(append(cycle, cycle[0]), " -> "))
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return &ConfigError{Field: "depends_on", Message: strings.Join(problems, "; ")}
+}
+
+// rotateCycle rotates a cycle so it starts at its smallest member.
+func rotateCycle(cycle []string) []string {
+	minIdx := 0
+	for i, name := range cycle {
+		if name < cycle[minIdx] {
+			minIdx = i
+		}
+	}
+	return append(append([]string{}, cycle[minIdx:]...), cycle[:minIdx]...)
+}
+
+func uniqueDeps(deps []string) []string {
+	seen := make(map[string]bool, len(deps))
+	result := make([]string, 0, len(deps))
+	for _, d := range deps {
+		if !seen[d] {
+			seen[d] = true
+			result = append(result, d)
+		}
+	}
+	return result
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// InstanceSeparator separates the service name from the instance number in
+// the name of a service instance, e.g. worker@2.
+const InstanceSeparator = "@"
+
+// InstanceData is the data available to the templates in the args, env and
+// working_dir of a multi-instance service.
+type InstanceData struct {
+	Instance int // instance number, starting at 0
+	Port     int // port + Instance, or 0 if no port is configured
+}
+
+// IsMultiInstance reports whether the service runs as multiple instances.
+func (c *ServiceConfig) IsMultiInstance() bool {
+	return c.Instances > 0
+}
+
+// InstanceName returns the name of instance i of a service.
+func InstanceName(name string, i int) string {
+	return name + InstanceSeparator + strconv.Itoa(i)
+}
+
+// SplitInstanceName splits the name of a service instance into the service
+// name and the instance number. ok is false if name is not an instance name.
+func SplitInstanceName(name string) (service string, instance int, ok bool) {
+	service, num, found := strings.Cut(name, InstanceSeparator)
+	if !found {
+		return name, 0, false
+	}
+	instance, err := strconv.Atoi(num)
+	if err != nil || instance < 0 {
+		return name, 0, false
+	}
+	return service, instance, true
+}
+
+// ForInstance returns the configuration of instance i of a multi-instance
+// service: a copy named <name>@<i> with the templates in its args, env and
+// working_dir expanded.
+func (c *ServiceConfig) ForInstance(i int) (*ServiceConfig, error) {
+	data := InstanceData{Instance: i}
+	if c.Port > 0 {
+		data.Port = c.Port + i
+	}
+
+	inst := *c
+	inst.Name = InstanceName(c.Name, i)
+	inst.Instances = 0
+	inst.Port = data.Port
+
+	var err error
+	if inst.WorkingDir, err = expandTemplate("working_dir", c.WorkingDir, data); err != nil {
+		return nil, err
+	}
+	if c.Args != nil {
+		inst.Args = make([]string, len(c.Args))
+		for j, arg := range c.Args {
+			if inst.Args[j], err = expandTemplate("args", arg, data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if c.Env != nil {
+		inst.Env = make(map[string]string, len(c.Env))
+		for k, v := range c.Env {
+			if inst.Env[k], err = expandTemplate("env."+k, v, data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &inst, nil
+}
+
+// expandTemplate executes s as a text/template with the instance data.
+func expandTemplate(field, s string, data InstanceData) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	tmpl, err := template.New(field).Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", &ConfigError{Field: field, Message: err.Error()}
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", &ConfigError{Field: field, Message: fmt.Sprintf("expand template: %v", err)}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Unlimited is the value of a resource limit without a bound.
+const Unlimited = ^uint64(0)
+
+// LimitsConfig holds the OS resource limits (rlimits) applied to a service.
+// Unset fields leave the limit inherited from the daemon unchanged.
+type LimitsConfig struct {
+	NoFile       uint64        `yaml:"nofile" json:"nofile,omitempty"` // max open files
+	NProc        uint64        `yaml:"nproc"  json:"nproc,omitempty"`  // max processes of the service's user
+	Core         string        `yaml:"core"   json:"core,omitempty"`   // max core dump size, e.g. 0 or 100MB
+	AddressSpace string        `yaml:"as"     json:"as,omitempty"`     // max virtual memory, e.g. 2GB
+	Data         string        `yaml:"data"   json:"data,omitempty"`   // max data segment size
+	CPU          time.Duration `yaml:"cpu"    json:"cpu,omitempty"`    // max CPU time, enforced with SIGXCPU
+}
+
+// Rlimit is a single resource limit resolved from LimitsConfig.
+type Rlimit struct {
+	Name  string // nofile, nproc, core, as, data or cpu
+	Value uint64 // bytes, seconds or a count; Unlimited for no bound
+}
+
+// Validate checks the limits.
+func (l *LimitsConfig) Validate() error {
+	for _, s := range l.sizes() {
+		if s.value == "" {
+			continue
+		}
+		if _, err := ParseSize(s.value); err != nil {
+			return &ConfigError{Field: "limits." + s.name, Message: err.Error()}
+		}
+	}
+	if l.CPU < 0 || (l.CPU > 0 && l.CPU < time.Second) {
+		return &ConfigError{Field: "limits.cpu", Message: "cpu limit must be at least 1s"}
+	}
+	return nil
+}
+
+// Rlimits returns the limits that are set, in a fixed order.
+func (l *LimitsConfig) Rlimits() []Rlimit {
+	var limits []Rlimit
+	if l.NoFile > 0 {
+		limits = append(limits, Rlimit{Name: "nofile", Value: l.NoFile})
+	}
+	if l.NProc > 0 {
+		limits = append(limits, Rlimit{Name: "nproc", Value: l.NProc})
+	}
+	for _, s := range l.sizes() {
+		if s.value != "" {
+			v, _ := ParseSize(s.value)
+			limits = append(limits, Rlimit{Name: s.name, Value: v})
+		}
+	}
+	if l.CPU > 0 {
+		limits = append(limits, Rlimit{Name: "cpu", Value: uint64(l.CPU / time.Second)})
+	}
+	return limits
+}
+
+type sizeLimit struct{ name, value string }
+
+func (l *LimitsConfig) sizes() []sizeLimit {
+	return []sizeLimit{{"core", l.Core}, {"as", l.AddressSpace}, {"data", l.Data}}
+}
+
+// ParseSize parses a size such as "512", "64KB", "100MB" or "2GiB". Units
+// are binary multiples; "unlimited" returns Unlimited.
+func ParseSize(s string) (uint64, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	if v == "UNLIMITED" || v == "INFINITY" {
+		return Unlimited, nil
+	}
+
+	units := []struct {
+		suffix string
+		mult   uint64
+	}{
+		{"TIB", 1 << 40}, {"GIB", 1 << 30}, {"MIB", 1 << 20}, {"KIB", 1 << 10},
+		{"TB", 1 << 40}, {"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10},
+		{"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10},
+		{"B", 1},
+	}
+	mult := uint64(1)
+	for _, u := range units {
+		if strings.HasSuffix(v, u.suffix) {
+			v = strings.TrimSpace(strings.TrimSuffix(v, u.suffix))
+			mult = u.mult
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return uint64(n * float64(mult)), nil
+}
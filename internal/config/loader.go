@@ -14,6 +14,7 @@ type Loader struct {
 	mu       sync.RWMutex
 	global   *GlobalConfig
 	services map[string]*ServiceConfig
+	files    map[string]serviceFile // service files by path, as last loaded or written
 }
 
 // NewLoader creates a new configuration loader.
@@ -21,11 +22,14 @@ func NewLoader() *Loader {
 	return &Loader{
 		global:   DefaultGlobalConfig(),
 		services: make(map[string]*ServiceConfig),
+		files:    make(map[string]serviceFile),
 	}
 }
 
 // LoadGlobal loads the global configuration from file.
-// If the file does not exist, defaults are used.
+// If the file does not exist, defaults are used. The current configuration
+// is kept if the file cannot be loaded, so LoadGlobal can be called again
+// to reload it.
 func (l *Loader) LoadGlobal() error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
@@ -44,6 +48,9 @@ func (l *Loader) LoadGlobal() error {
 	if err := yaml.Unmarshal(data, cfg); err != nil {
 		return fmt.Errorf("parse global config: %w", err)
 	}
+	if err := cfg.Daemon.Validate(); err != nil {
+		return err
+	}
 	l.global = cfg
 	return nil
 }
@@ -56,39 +63,27 @@ func (l *Loader) writeDefaultGlobal(path string) error {
 	return os.WriteFile(path, data, 0644)
 }
 
-// LoadServices loads all service configurations from the services directory.
-func (l *Loader) LoadServices() error {
+// LoadServices loads all service files from the services directory,
+// replacing the services loaded before. A file that fails to load, or that
+// defines a service with a missing dependency or in a dependency cycle, is
+// skipped and returned in the errors; the other services are loaded.
+func (l *Loader) LoadServices() ([]FileError, error) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	svcDir := ServicesDir()
-	entries, err := os.ReadDir(svcDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return fmt.Errorf("read services dir: %w", err)
-	}
-
-	services := make(map[string]*ServiceConfig)
-	for _, entry := range entries {
-		if entry.IsDir() {
-			continue
-		}
-		ext := filepath.Ext(entry.Name())
-		if ext != ".yaml" && ext != ".yml" {
-			continue
-		}
+	l.services = make(map[string]*ServiceConfig)
+	l.files = make(map[string]serviceFile)
+	changes, err := l.reloadServices()
+	return changes.Errors, err
+}
 
-		svc, err := l.loadServiceFile(filepath.Join(svcDir, entry.Name()))
-		if err != nil {
-			return fmt.Errorf("load service %s: %w", entry.Name(), err)
-		}
-		services[svc.Name] = svc
+// isServiceFile reports whether a directory entry is a service file.
+func isServiceFile(entry os.DirEntry) bool {
+	if entry.IsDir() {
+		return false
 	}
-
-	l.services = services
-	return nil
+	ext := filepath.Ext(entry.Name())
+	return ext == ".yaml" || ext == ".yml"
 }
 
 func (l *Loader) loadServiceFile(path string) (*ServiceConfig, error) {
@@ -104,6 +99,9 @@ func (l *Loader) loadServiceFile(path string) (*ServiceConfig, error) {
 	if err := svc.Validate(); err != nil {
 		return nil, fmt.Errorf("validate %s: %w", path, err)
 	}
+	if err := l.global.Daemon.ValidateService(&svc); err != nil {
+		return nil, fmt.Errorf("validate %s: %w", path, err)
+	}
 	return &svc, nil
 }
 
@@ -139,34 +137,53 @@ func (l *Loader) SaveService(svc *ServiceConfig) error {
 	if err := svc.Validate(); err != nil {
 		return err
 	}
+	if err := l.GetGlobal().Daemon.ValidateService(svc); err != nil {
+		return err
+	}
 
 	data, err := yaml.Marshal(svc)
 	if err != nil {
 		return fmt.Errorf("marshal service config: %w", err)
 	}
 
-	path := filepath.Join(ServicesDir(), svc.Name+".yaml")
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	path := l.servicePath(svc.Name)
 	if err := os.WriteFile(path, data, 0644); err != nil {
 		return fmt.Errorf("write service config: %w", err)
 	}
 
-	l.mu.Lock()
 	l.services[svc.Name] = svc
-	l.mu.Unlock()
-
+	if info, err := os.Stat(path); err == nil {
+		l.files[path] = newServiceFile(info, svc.Name)
+	}
 	return nil
 }
 
 // RemoveService removes a service configuration from disk and memory.
 func (l *Loader) RemoveService(name string) error {
-	path := filepath.Join(ServicesDir(), name+".yaml")
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	path := l.servicePath(name)
 	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("remove service config: %w", err)
 	}
 
-	l.mu.Lock()
 	delete(l.services, name)
-	l.mu.Unlock()
-
+	delete(l.files, path)
 	return nil
 }
+
+// servicePath returns the file a service was loaded from, or
+// <name>.yaml in the services directory for a new service. The caller
+// must hold l.mu.
+func (l *Loader) servicePath(name string) string {
+	for path, f := range l.files {
+		if f.name == name {
+			return path
+		}
+	}
+	return filepath.Join(ServicesDir(), name+".yaml")
+}
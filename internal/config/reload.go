@@ -0,0 +1,199 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// serviceFile identifies the version of a service file that was last
+// loaded or written.
+type serviceFile struct {
+	modTime time.Time
+	size    int64
+	name    string // service loaded from the file, empty if none
+}
+
+func newServiceFile(info os.FileInfo, name string) serviceFile {
+	return serviceFile{modTime: info.ModTime(), size: info.Size(), name: name}
+}
+
+// sameVersion reports whether f and other describe the same file contents.
+func (f serviceFile) sameVersion(other serviceFile) bool {
+	return f.modTime.Equal(other.modTime) && f.size == other.size
+}
+
+// FileError is a service file that could not be loaded.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e FileError) Error() string {
+	return filepath.Base(e.Path) + ": " + e.Err.Error()
+}
+
+// Service returns the name of the service the file is expected to define,
+// derived from its file name.
+func (e FileError) Service() string {
+	return strings.TrimSuffix(filepath.Base(e.Path), filepath.Ext(e.Path))
+}
+
+// ServiceChanges lists the changes applied by ReloadServices.
+type ServiceChanges struct {
+	Added    []*ServiceConfig
+	Updated  []*ServiceConfig
+	Previous map[string]*ServiceConfig // previous configuration of updated services
+	Removed  []*ServiceConfig
+	Errors   []FileError
+}
+
+// ReloadServices rescans the services directory and applies the service
+// files that were added, changed or removed since they were last loaded or
+// written. A file that fails to load is reported in Errors and leaves its
+// service as it was; so does a change that would leave a dependency
+// missing or create a dependency cycle. Each broken file is reported once
+// until it changes again.
+func (l *Loader) ReloadServices() (ServiceChanges, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.reloadServices()
+}
+
+// reloadServices implements ReloadServices. The caller must hold l.mu, also
+// while the directory is read, so that files written by SaveService in the
+// meantime are not taken for external changes.
+func (l *Loader) reloadServices() (ServiceChanges, error) {
+	changes := ServiceChanges{Previous: make(map[string]*ServiceConfig)}
+
+	svcDir := ServicesDir()
+	entries, err := os.ReadDir(svcDir)
+	if err != nil && !os.IsNotExist(err) {
+		return changes, fmt.Errorf("read services dir: %w", err)
+	}
+
+	next := make(map[string]*ServiceConfig, len(l.services))
+	files := make(map[string]serviceFile, len(entries))
+	changed := make(map[string]string) // changed service name -> file path
+	modified := false
+
+	// Entries are sorted by file name, so duplicate names resolve stably.
+	for _, entry := range entries {
+		if !isServiceFile(entry) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(svcDir, entry.Name())
+		old, known := l.files[path]
+		cur := newServiceFile(info, old.name)
+
+		keepOld := func() {
+			files[path] = cur
+			if old.name != "" && l.services[old.name] != nil && next[old.name] == nil {
+				next[old.name] = l.services[old.name]
+			}
+		}
+
+		if known && old.sameVersion(cur) {
+			keepOld()
+			continue
+		}
+		modified = true
+
+		svc, err := l.loadServiceFile(path)
+		if err == nil && next[svc.Name] != nil {
+			err = fmt.Errorf("service %s is already defined in another file", svc.Name)
+		}
+		if err != nil {
+			changes.Errors = append(changes.Errors, FileError{Path: path, Err: err})
+			keepOld()
+			continue
+		}
+		next[svc.Name] = svc
+		files[path] = newServiceFile(info, svc.Name)
+		changed[svc.Name] = path
+	}
+	for path := range l.files {
+		if _, ok := files[path]; !ok {
+			modified = true
+		}
+	}
+	if !modified {
+		return changes, nil
+	}
+
+	// Revert changes that break the dependency graph until it is valid.
+	revert := func(name string, err error) {
+		changes.Errors = append(changes.Errors, FileError{Path: changed[name], Err: err})
+		delete(changed, name)
+		if old := l.services[name]; old != nil {
+			next[name] = old
+		} else {
+			delete(next, name)
+		}
+	}
+	for {
+		reverted := false
+		for name, deps := range MissingDependencies(next) {
+			for _, dep := range deps {
+				if old := l.services[dep]; old != nil {
+					// Keep a removed service that others still depend on
+					next[dep] = old
+					changes.Errors = append(changes.Errors, FileError{
+						Path: l.servicePath(dep),
+						Err:  fmt.Errorf("service %s cannot be removed, %s depends on it", dep, name),
+					})
+					reverted = true
+				} else if _, ok := changed[name]; ok {
+					revert(name, fmt.Errorf("depends on unknown service %s", dep))
+					reverted = true
+					break
+				}
+			}
+		}
+		for _, cycle := range DependencyCycles(next) {
+			for _, name := range cycle {
+				if _, ok := changed[name]; ok {
+					revert(name, fmt.Errorf("dependency cycle: %s -> %s", strings.Join(cycle, " -> "), cycle[0]))
+					reverted = true
+				}
+			}
+		}
+		if !reverted {
+			break
+		}
+	}
+
+	for name, svc := range next {
+		old, ok := l.services[name]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, svc)
+		case old != svc:
+			changes.Updated = append(changes.Updated, svc)
+			changes.Previous[name] = old
+		}
+	}
+	for name, old := range l.services {
+		if _, ok := next[name]; !ok {
+			changes.Removed = append(changes.Removed, old)
+		}
+	}
+	sortServices(changes.Added)
+	sortServices(changes.Updated)
+	sortServices(changes.Removed)
+
+	l.services = next
+	l.files = files
+	return changes, nil
+}
+
+func sortServices(services []*ServiceConfig) {
+	sort.Slice(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+}
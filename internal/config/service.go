@@ -1,23 +1,63 @@
 package config
 
-import "time"
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BAIGUANGMEI/goser/internal/schedule"
+)
 
 // ServiceConfig defines a managed service's configuration.
 type ServiceConfig struct {
-	Name         string            `yaml:"name"          json:"name"`
-	Command      string            `yaml:"command"       json:"command"`
-	Args         []string          `yaml:"args"          json:"args,omitempty"`
-	WorkingDir   string            `yaml:"working_dir"   json:"working_dir,omitempty"`
-	Env          map[string]string `yaml:"env"           json:"env,omitempty"`
-	AutoStart    bool              `yaml:"auto_start"    json:"auto_start"`
-	AutoRestart  bool              `yaml:"auto_restart"  json:"auto_restart"`
-	MaxRestarts  int               `yaml:"max_restarts"  json:"max_restarts"`
-	RestartDelay time.Duration     `yaml:"restart_delay" json:"restart_delay"`
-	StopSignal   string            `yaml:"stop_signal"   json:"stop_signal"`
-	StopTimeout  time.Duration     `yaml:"stop_timeout"  json:"stop_timeout"`
-	LogFile      string            `yaml:"log_file"      json:"log_file"`
-	DependsOn    []string          `yaml:"depends_on"    json:"depends_on,omitempty"`
-	HealthCheck  *HealthCheckConfig `yaml:"health_check" json:"health_check,omitempty"`
+	Name                    string               `yaml:"name"          json:"name"`
+	Command                 string               `yaml:"command"       json:"command"`
+	Type                    string               `yaml:"type"          json:"type,omitempty"` // simple | oneshot
+	RemainAfterExit         bool                 `yaml:"remain_after_exit" json:"remain_after_exit,omitempty"`
+	Instances               int                  `yaml:"instances"     json:"instances,omitempty"` // run as <name>@0 .. <name>@N-1
+	Port                    int                  `yaml:"port"          json:"port,omitempty"`      // port of instance 0, see InstanceData
+	Args                    []string             `yaml:"args"          json:"args,omitempty"`
+	WorkingDir              string               `yaml:"working_dir"   json:"working_dir,omitempty"`
+	Env                     map[string]string    `yaml:"env"           json:"env,omitempty"`
+	User                    string               `yaml:"user"          json:"user,omitempty"`
+	Group                   string               `yaml:"group"         json:"group,omitempty"`
+	SupplementaryGroups     []string             `yaml:"supplementary_groups" json:"supplementary_groups,omitempty"`
+	AutoStart               bool                 `yaml:"auto_start"    json:"auto_start"`
+	AutoRestart             bool                 `yaml:"auto_restart"  json:"auto_restart"`
+	Restart                 string               `yaml:"restart"       json:"restart"` // always | on-failure | on-abnormal | never
+	SuccessExitCodes        []int                `yaml:"success_exit_codes"         json:"success_exit_codes,omitempty"`
+	RestartPreventExitCodes []int                `yaml:"restart_prevent_exit_codes" json:"restart_prevent_exit_codes,omitempty"`
+	MaxRestarts             int                  `yaml:"max_restarts"  json:"max_restarts"`
+	RestartDelay            time.Duration        `yaml:"restart_delay" json:"restart_delay"`
+	RestartPolicy           *RestartPolicyConfig `yaml:"restart_policy" json:"restart_policy,omitempty"`
+	StopSignal              string               `yaml:"stop_signal"   json:"stop_signal"`
+	StopTimeout             time.Duration        `yaml:"stop_timeout"  json:"stop_timeout"`
+	StopCommand             string               `yaml:"stop_command"  json:"stop_command,omitempty"`
+	ReloadSignal            string               `yaml:"reload_signal" json:"reload_signal,omitempty"` // sent by the reload-signal apply mode
+	KillMode                string               `yaml:"kill_mode"     json:"kill_mode,omitempty"`     // group | process | mixed
+	OrphanPolicy            string               `yaml:"orphan_policy" json:"orphan_policy,omitempty"` // adopt | kill | kill-on-daemon-death
+	LogFile                 string               `yaml:"log_file"      json:"log_file"`
+	DependsOn               []string             `yaml:"depends_on"    json:"depends_on,omitempty"`
+	ReadyTimeout            time.Duration        `yaml:"ready_timeout" json:"ready_timeout"`
+	HealthCheck             *HealthCheckConfig   `yaml:"health_check" json:"health_check,omitempty"`
+	Limits                  *LimitsConfig        `yaml:"limits"        json:"limits,omitempty"`
+	Watchdog                *WatchdogConfig      `yaml:"watchdog"      json:"watchdog,omitempty"`
+	Nice                    *int                 `yaml:"nice"          json:"nice,omitempty"` // -20 (highest) to 19 (lowest)
+	IOPrio                  *IOPrioConfig        `yaml:"ioprio"        json:"ioprio,omitempty"`
+	OOMScoreAdj             *int                 `yaml:"oom_score_adj" json:"oom_score_adj,omitempty"`           // -1000 to 1000
+	Umask                   string               `yaml:"umask"         json:"umask,omitempty"`                   // octal, e.g. "0027"
+	Schedule                string               `yaml:"schedule"      json:"schedule,omitempty"`                // cron expression, @hourly, @every 10m, ...
+	Timezone                string               `yaml:"timezone"      json:"timezone,omitempty"`                // IANA time zone of schedule and restart_at, default local
+	ConcurrencyPolicy       string               `yaml:"concurrency_policy" json:"concurrency_policy,omitempty"` // skip | queue
+	MaxRuntime              time.Duration        `yaml:"max_runtime"   json:"max_runtime,omitempty"`
+	MaxRuntimeAction        string               `yaml:"max_runtime_action" json:"max_runtime_action,omitempty"` // stop | restart
+	RestartEvery            time.Duration        `yaml:"restart_every" json:"restart_every,omitempty"`
+	RestartAt               string               `yaml:"restart_at"    json:"restart_at,omitempty"` // cron expression
+
+	cron        schedule.Schedule // parsed Schedule, set by Validate
+	restartCron schedule.Schedule // parsed RestartAt, set by Validate
 }
 
 // Validate checks the service configuration for required fields and applies defaults.
@@ -25,32 +65,373 @@ func (c *ServiceConfig) Validate() error {
 	if c.Name == "" {
 		return ErrMissingName
 	}
+	if strings.Contains(c.Name, InstanceSeparator) {
+		return &ConfigError{Field: "name", Message: "service name must not contain " + InstanceSeparator}
+	}
 	if c.Command == "" {
 		return ErrMissingCommand
 	}
+	for _, dep := range c.DependsOn {
+		if dep == c.Name {
+			return ErrSelfDependency
+		}
+	}
 	// Apply defaults
+	switch c.Type {
+	case "":
+		c.Type = "simple"
+	case "simple", "oneshot":
+	default:
+		return &ConfigError{Field: "type", Message: "type must be one of simple, oneshot"}
+	}
+	switch c.Restart {
+	case "":
+		// Derive the restart condition from the legacy auto_restart flag
+		c.Restart = "never"
+		if c.AutoRestart {
+			c.Restart = "on-failure"
+		}
+	case "always", "on-failure", "on-abnormal", "never":
+	default:
+		return &ConfigError{Field: "restart", Message: "restart must be one of always, on-failure, on-abnormal, never"}
+	}
+	c.AutoRestart = c.Restart != "never"
 	if c.MaxRestarts == 0 {
 		c.MaxRestarts = 5
 	}
 	if c.RestartDelay == 0 {
 		c.RestartDelay = 5 * time.Second
 	}
+	if c.RestartPolicy == nil {
+		c.RestartPolicy = &RestartPolicyConfig{}
+	}
+	if err := c.RestartPolicy.Validate(); err != nil {
+		return err
+	}
 	if c.StopSignal == "" {
 		c.StopSignal = "SIGTERM"
 	}
+	c.StopSignal = normalizeSignal(c.StopSignal)
+	if !isStopSignal(c.StopSignal) {
+		return &ConfigError{Field: "stop_signal", Message: "unsupported signal " + c.StopSignal + " (expected one of " + strings.Join(StopSignals, ", ") + ")"}
+	}
+	if c.ReloadSignal == "" {
+		c.ReloadSignal = "SIGHUP"
+	}
+	c.ReloadSignal = normalizeSignal(c.ReloadSignal)
+	if !isStopSignal(c.ReloadSignal) || c.ReloadSignal == "SIGKILL" {
+		return &ConfigError{Field: "reload_signal", Message: "unsupported signal " + c.ReloadSignal}
+	}
 	if c.StopTimeout == 0 {
 		c.StopTimeout = 10 * time.Second
 	}
+	switch c.KillMode {
+	case "":
+		c.KillMode = "group"
+	case "group", "process", "mixed":
+	default:
+		return &ConfigError{Field: "kill_mode", Message: "kill_mode must be one of group, process, mixed"}
+	}
+	switch c.OrphanPolicy {
+	case "":
+		c.OrphanPolicy = "adopt"
+	case "adopt", "kill", "kill-on-daemon-death":
+	default:
+		return &ConfigError{Field: "orphan_policy", Message: "orphan_policy must be one of adopt, kill, kill-on-daemon-death"}
+	}
 	if c.LogFile == "" {
 		c.LogFile = "auto"
 	}
+	if c.ReadyTimeout == 0 {
+		c.ReadyTimeout = 60 * time.Second
+	}
+	if c.HealthCheck != nil {
+		if err := c.HealthCheck.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Limits != nil {
+		if err := c.Limits.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Watchdog != nil {
+		if err := c.Watchdog.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Nice != nil && (*c.Nice < -20 || *c.Nice > 19) {
+		return &ConfigError{Field: "nice", Message: "nice must be between -20 and 19"}
+	}
+	if c.IOPrio != nil {
+		if err := c.IOPrio.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.OOMScoreAdj != nil && (*c.OOMScoreAdj < -1000 || *c.OOMScoreAdj > 1000) {
+		return &ConfigError{Field: "oom_score_adj", Message: "oom_score_adj must be between -1000 and 1000"}
+	}
+	if c.Umask != "" {
+		if _, err := c.UmaskValue(); err != nil {
+			return &ConfigError{Field: "umask", Message: "umask must be an octal value between 0000 and 0777"}
+		}
+	}
+	if err := c.validateInstances(); err != nil {
+		return err
+	}
+	if err := c.validateOneshot(); err != nil {
+		return err
+	}
+	if err := c.validateSchedule(); err != nil {
+		return err
+	}
+	if err := c.validatePlannedRestarts(); err != nil {
+		return err
+	}
+	if err := c.validateCredentials(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (c *ServiceConfig) validateInstances() error {
+	if c.Instances < 0 {
+		return &ConfigError{Field: "instances", Message: "instances must not be negative"}
+	}
+	if c.Port < 0 || c.Port > 65535 || (c.Port > 0 && c.Port+c.Instances-1 > 65535) {
+		return &ConfigError{Field: "port", Message: "port must be between 1 and 65535 for every instance"}
+	}
+	if c.IsMultiInstance() {
+		// Instance 0 catches errors in the templates
+		if _, err := c.ForInstance(0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ServiceConfig) validateOneshot() error {
+	if !c.IsOneshot() {
+		if c.RemainAfterExit {
+			return &ConfigError{Field: "remain_after_exit", Message: "remain_after_exit requires type: oneshot"}
+		}
+		return nil
+	}
+	if c.Restart == "always" {
+		return &ConfigError{Field: "restart", Message: "restart: always cannot be used with type: oneshot"}
+	}
+	if c.HealthCheck != nil {
+		return &ConfigError{Field: "health_check", Message: "health_check cannot be used with type: oneshot"}
+	}
+	if c.RemainAfterExit && c.Schedule != "" {
+		return &ConfigError{Field: "remain_after_exit", Message: "remain_after_exit cannot be used with a schedule"}
+	}
+	return nil
+}
+
+// IsOneshot reports whether the service runs to completion instead of
+// running continuously.
+func (c *ServiceConfig) IsOneshot() bool {
+	return c.Type == "oneshot"
+}
+
+// location returns the time zone of schedule and restart_at.
+func (c *ServiceConfig) location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, &ConfigError{Field: "timezone", Message: "unknown time zone " + c.Timezone}
+	}
+	return loc, nil
+}
+
+func (c *ServiceConfig) validateSchedule() error {
+	c.cron = nil
+	if c.Schedule == "" {
+		return nil
+	}
+	loc, err := c.location()
+	if err != nil {
+		return err
+	}
+	cron, err := schedule.Parse(c.Schedule, loc)
+	if err != nil {
+		return &ConfigError{Field: "schedule", Message: err.Error()}
+	}
+	if cron.Next(time.Now()).IsZero() {
+		return &ConfigError{Field: "schedule", Message: "schedule " + c.Schedule + " never fires"}
+	}
+	switch c.ConcurrencyPolicy {
+	case "":
+		c.ConcurrencyPolicy = "skip"
+	case "skip", "queue":
+	default:
+		return &ConfigError{Field: "concurrency_policy", Message: "concurrency_policy must be one of skip, queue"}
+	}
+	if c.Restart == "always" {
+		return &ConfigError{Field: "restart", Message: "restart: always cannot be used with a schedule"}
+	}
+	c.cron = cron
+	return nil
+}
+
+func (c *ServiceConfig) validatePlannedRestarts() error {
+	if c.MaxRuntime < 0 || c.RestartEvery < 0 {
+		return &ConfigError{Field: "max_runtime", Message: "max_runtime and restart_every must not be negative"}
+	}
+	switch c.MaxRuntimeAction {
+	case "":
+		if c.MaxRuntime > 0 {
+			c.MaxRuntimeAction = "stop"
+		}
+	case "stop", "restart":
+	default:
+		return &ConfigError{Field: "max_runtime_action", Message: "max_runtime_action must be one of stop, restart"}
+	}
+
+	c.restartCron = nil
+	if c.RestartAt == "" {
+		return nil
+	}
+	loc, err := c.location()
+	if err != nil {
+		return err
+	}
+	cron, err := schedule.Parse(c.RestartAt, loc)
+	if err != nil {
+		return &ConfigError{Field: "restart_at", Message: err.Error()}
+	}
+	if cron.Next(time.Now()).IsZero() {
+		return &ConfigError{Field: "restart_at", Message: "restart_at " + c.RestartAt + " never fires"}
+	}
+	c.restartCron = cron
 	return nil
 }
 
+// RestartAtSchedule returns the parsed restart_at schedule, or nil if no
+// restart_at is configured.
+func (c *ServiceConfig) RestartAtSchedule() schedule.Schedule {
+	return c.restartCron
+}
+
+// CronSchedule returns the parsed schedule of a scheduled service, or nil if
+// the service is not scheduled.
+func (c *ServiceConfig) CronSchedule() schedule.Schedule {
+	return c.cron
+}
+
+// IsSuccessExit reports whether an exit code counts as a clean exit:
+// 0 or one of success_exit_codes.
+func (c *ServiceConfig) IsSuccessExit(code int) bool {
+	return code == 0 || containsCode(c.SuccessExitCodes, code)
+}
+
+// PreventsRestart reports whether an exit code is listed in
+// restart_prevent_exit_codes.
+func (c *ServiceConfig) PreventsRestart(code int) bool {
+	return containsCode(c.RestartPreventExitCodes, code)
+}
+
+func containsCode(codes []int, code int) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// UmaskValue returns the parsed umask.
+func (c *ServiceConfig) UmaskValue() (int, error) {
+	v, err := strconv.ParseUint(c.Umask, 8, 32)
+	if err != nil || v > 0o777 {
+		return 0, fmt.Errorf("invalid umask %q", c.Umask)
+	}
+	return int(v), nil
+}
+
+// launchSettings are the settings a process is started with. Changing them
+// only affects a running process once it is restarted.
+type launchSettings struct {
+	Command             string
+	Args                []string
+	WorkingDir          string
+	Env                 map[string]string
+	User                string
+	Group               string
+	SupplementaryGroups []string
+	OrphanPolicy        string
+	Limits              *LimitsConfig
+	Nice                *int
+	IOPrio              *IOPrioConfig
+	OOMScoreAdj         *int
+	Umask               string
+}
+
+func (c *ServiceConfig) launchSettings() launchSettings {
+	s := launchSettings{
+		Command:             c.Command,
+		Args:                c.Args,
+		WorkingDir:          c.WorkingDir,
+		Env:                 c.Env,
+		User:                c.User,
+		Group:               c.Group,
+		SupplementaryGroups: c.SupplementaryGroups,
+		OrphanPolicy:        c.OrphanPolicy,
+		Limits:              c.Limits,
+		Nice:                c.Nice,
+		IOPrio:              c.IOPrio,
+		OOMScoreAdj:         c.OOMScoreAdj,
+		Umask:               c.Umask,
+	}
+	// Empty and missing lists are the same to a process
+	if len(s.Args) == 0 {
+		s.Args = nil
+	}
+	if len(s.Env) == 0 {
+		s.Env = nil
+	}
+	if len(s.SupplementaryGroups) == 0 {
+		s.SupplementaryGroups = nil
+	}
+	return s
+}
+
+// NeedsRestart reports whether a process started with c has to be
+// restarted to run with other. The remaining settings, such as the restart
+// policy or the health check, apply to a running process immediately.
+func (c *ServiceConfig) NeedsRestart(other *ServiceConfig) bool {
+	return !reflect.DeepEqual(c.launchSettings(), other.launchSettings())
+}
+
+// StopSignals lists the signal names accepted for stop_signal.
+var StopSignals = []string{"SIGTERM", "SIGINT", "SIGHUP", "SIGQUIT", "SIGUSR1", "SIGUSR2", "SIGKILL"}
+
+// normalizeSignal converts names like "term" or "sigterm" to "SIGTERM".
+func normalizeSignal(name string) string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	return name
+}
+
+func isStopSignal(name string) bool {
+	for _, s := range StopSignals {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
 // Errors for service configuration validation.
 var (
 	ErrMissingName    = &ConfigError{Field: "name", Message: "service name is required"}
 	ErrMissingCommand = &ConfigError{Field: "command", Message: "command is required"}
+	ErrSelfDependency = &ConfigError{Field: "depends_on", Message: "service cannot depend on itself"}
 )
 
 // ConfigError represents a configuration validation error.
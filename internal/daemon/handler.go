@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -37,10 +38,12 @@ func (s *Server) setupRoutes() {
 	{
 		// Daemon status
 		api.GET("/daemon/status", s.handleDaemonStatus)
+		api.POST("/daemon/reload", s.handleDaemonReload)
 
 		// Services
 		api.GET("/services", s.handleListServices)
 		api.GET("/services/:name", s.handleGetService)
+		api.GET("/services/:name/config", s.handleGetServiceConfig)
 		api.POST("/services", s.handleCreateService)
 		api.PUT("/services/:name", s.handleUpdateService)
 		api.DELETE("/services/:name", s.handleDeleteService)
@@ -49,9 +52,16 @@ func (s *Server) setupRoutes() {
 		api.POST("/services/:name/start", s.handleStartService)
 		api.POST("/services/:name/stop", s.handleStopService)
 		api.POST("/services/:name/restart", s.handleRestartService)
+		api.PUT("/services/:name/scale", s.handleScaleService)
 
 		// Logs
 		api.GET("/services/:name/logs", s.handleGetLogs)
+
+		// Resource usage history
+		api.GET("/services/:name/metrics", s.handleGetMetrics)
+
+		// Dependency graph
+		api.GET("/graph", s.handleGetGraph)
 	}
 
 	// WebSocket
@@ -61,24 +71,43 @@ func (s *Server) setupRoutes() {
 // --- Daemon ---
 
 func (s *Server) handleDaemonStatus(c *gin.Context) {
-	total, running, stopped, failed := s.mgr.Stats()
+	total, running, stopped, failed, backoff, crashLoop := s.mgr.Stats()
 	uptime := time.Since(s.startedAt)
 
 	c.JSON(http.StatusOK, model.APIResponse{
 		Success: true,
 		Data: model.DaemonStatus{
-			Running:      true,
-			PID:          0, // Will be set by caller
-			StartedAt:    s.startedAt,
-			Uptime:       formatDuration(uptime),
-			ServiceCount: total,
-			RunningCount: running,
-			StoppedCount: stopped,
-			FailedCount:  failed,
+			Running:        true,
+			PID:            0, // Will be set by caller
+			StartedAt:      s.startedAt,
+			Uptime:         formatDuration(uptime),
+			ServiceCount:   total,
+			RunningCount:   running,
+			StoppedCount:   stopped,
+			FailedCount:    failed,
+			BackoffCount:   backoff,
+			CrashLoopCount: crashLoop,
 		},
 	})
 }
 
+func (s *Server) handleDaemonReload(c *gin.Context) {
+	result, err := s.Reload()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Success: true,
+		Message: "configuration reloaded",
+		Data:    result,
+	})
+}
+
 // --- Services CRUD ---
 
 func (s *Server) handleListServices(c *gin.Context) {
@@ -105,6 +134,21 @@ func (s *Server) handleGetService(c *gin.Context) {
 	})
 }
 
+func (s *Server) handleGetServiceConfig(c *gin.Context) {
+	svc, err := s.mgr.GetServiceConfig(c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, model.APIResponse{
+		Success: true,
+		Data:    svc,
+	})
+}
+
 func (s *Server) handleCreateService(c *gin.Context) {
 	var svc config.ServiceConfig
 	if err := c.ShouldBindJSON(&svc); err != nil {
@@ -140,8 +184,9 @@ func (s *Server) handleUpdateService(c *gin.Context) {
 		return
 	}
 	svc.Name = name
+	opts := model.UpdateOptions{Apply: c.Query("apply")}
 
-	if err := s.mgr.UpdateService(&svc); err != nil {
+	if err := s.mgr.UpdateService(&svc, opts); err != nil {
 		c.JSON(http.StatusBadRequest, model.APIResponse{
 			Success: false,
 			Error:   err.Error(),
@@ -157,11 +202,11 @@ func (s *Server) handleUpdateService(c *gin.Context) {
 
 func (s *Server) handleDeleteService(c *gin.Context) {
 	name := c.Param("name")
-	if err := s.mgr.RemoveService(name); err != nil {
-		c.JSON(http.StatusBadRequest, model.APIResponse{
-			Success: false,
-			Error:   err.Error(),
-		})
+	opts := model.StopOptions{
+		Cascade: c.Query("cascade") == "true",
+	}
+	if err := s.mgr.RemoveService(name, opts); err != nil {
+		respondActionError(c, err)
 		return
 	}
 
@@ -175,11 +220,12 @@ func (s *Server) handleDeleteService(c *gin.Context) {
 
 func (s *Server) handleStartService(c *gin.Context) {
 	name := c.Param("name")
-	if err := s.mgr.StartService(name); err != nil {
-		c.JSON(http.StatusBadRequest, model.APIResponse{
-			Success: false,
-			Error:   err.Error(),
-		})
+	opts := model.StartOptions{
+		WithDependencies: c.Query("deps") == "true",
+		Transient:        c.Query("transient") == "true",
+	}
+	if err := s.mgr.StartService(name, opts); err != nil {
+		respondActionError(c, err)
 		return
 	}
 
@@ -191,11 +237,12 @@ func (s *Server) handleStartService(c *gin.Context) {
 
 func (s *Server) handleStopService(c *gin.Context) {
 	name := c.Param("name")
-	if err := s.mgr.StopService(name); err != nil {
-		c.JSON(http.StatusBadRequest, model.APIResponse{
-			Success: false,
-			Error:   err.Error(),
-		})
+	opts := model.StopOptions{
+		Cascade:   c.Query("cascade") == "true",
+		Transient: c.Query("transient") == "true",
+	}
+	if err := s.mgr.StopService(name, opts); err != nil {
+		respondActionError(c, err)
 		return
 	}
 
@@ -221,6 +268,57 @@ func (s *Server) handleRestartService(c *gin.Context) {
 	})
 }
 
+func (s *Server) handleScaleService(c *gin.Context) {
+	name := c.Param("name")
+	var req model.ScaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Success: false,
+			Error:   "invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	if err := s.mgr.ScaleService(name, req.Instances); err != nil {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Success: true,
+		Message: "service scaled",
+	})
+}
+
+// respondActionError writes the error of a service action. Dependency
+// conflicts are reported with status 409 and the affected services as data.
+func respondActionError(c *gin.Context, err error) {
+	var depErr *model.DependencyError
+	var dependentsErr *model.DependentsError
+	switch {
+	case errors.As(err, &depErr):
+		c.JSON(http.StatusConflict, model.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+			Data:    depErr,
+		})
+	case errors.As(err, &dependentsErr):
+		c.JSON(http.StatusConflict, model.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+			Data:    dependentsErr,
+		})
+	default:
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+}
+
 // --- Logs ---
 
 func (s *Server) handleGetLogs(c *gin.Context) {
@@ -246,6 +344,71 @@ func (s *Server) handleGetLogs(c *gin.Context) {
 	})
 }
 
+// --- Metrics ---
+
+// handleGetMetrics returns a service's resource usage history. "since" is a
+// duration before now (e.g. 15m) or an RFC 3339 timestamp, "step" an
+// optional duration to downsample the points to.
+func (s *Server) handleGetMetrics(c *gin.Context) {
+	since, err := parseSince(c.Query("since"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.APIResponse{
+			Success: false,
+			Error:   "invalid since: " + err.Error(),
+		})
+		return
+	}
+	var step time.Duration
+	if v := c.Query("step"); v != "" {
+		if step, err = time.ParseDuration(v); err != nil || step < 0 {
+			c.JSON(http.StatusBadRequest, model.APIResponse{
+				Success: false,
+				Error:   "invalid step: " + v,
+			})
+			return
+		}
+	}
+
+	metrics, err := s.mgr.Metrics(c.Param("name"), since, step)
+	if err != nil {
+		c.JSON(http.StatusNotFound, model.APIResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Success: true,
+		Data:    metrics,
+	})
+}
+
+func parseSince(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// --- Dependency graph ---
+
+func (s *Server) handleGetGraph(c *gin.Context) {
+	graph := s.mgr.DependencyGraph()
+	if c.Query("format") == "dot" {
+		c.String(http.StatusOK, graph.DOT())
+		return
+	}
+
+	c.JSON(http.StatusOK, model.APIResponse{
+		Success: true,
+		Data:    graph,
+	})
+}
+
 // --- WebSocket ---
 
 func (s *Server) handleWebSocket(c *gin.Context) {
@@ -0,0 +1,120 @@
+package daemon
+
+import (
+	"context"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/BAIGUANGMEI/goser/internal/logger"
+	"github.com/BAIGUANGMEI/goser/internal/model"
+)
+
+// Reload loads the global configuration again and applies the changed
+// daemon settings that can change while the daemon runs: the listen
+// address, the PID file, the log directory and the log rotation limits.
+// The other changed settings take effect when the daemon restarts. The
+// configuration is left unchanged if it cannot be loaded.
+func (s *Server) Reload() (*model.DaemonReload, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	old := s.loader.GetGlobal()
+	if err := s.loader.LoadGlobal(); err != nil {
+		return nil, err
+	}
+	cur := s.loader.GetGlobal()
+
+	result := &model.DaemonReload{Applied: []string{}, RestartRequired: []string{}}
+	fail := func(key string, err error) {
+		logger.Get().Errorf("reload: failed to apply %s: %v", key, err)
+		if result.Failed == nil {
+			result.Failed = make(map[string]string)
+		}
+		result.Failed[key] = err.Error()
+	}
+
+	var logKeys []string
+	for _, key := range old.Daemon.Changes(&cur.Daemon) {
+		switch key {
+		case "listen":
+			if err := s.rebind(cur.Daemon.Listen); err != nil {
+				fail(key, err)
+				continue
+			}
+		case "pid_file":
+			if err := writePIDFile(cur.Daemon.PIDFile); err != nil {
+				fail(key, err)
+				continue
+			}
+			_ = os.Remove(old.Daemon.PIDFile)
+		case "log_dir", "max_log_size", "log_retention":
+			logKeys = append(logKeys, key)
+			continue
+		default:
+			result.RestartRequired = append(result.RestartRequired, key)
+			continue
+		}
+		result.Applied = append(result.Applied, key)
+	}
+
+	if len(logKeys) > 0 {
+		if err := logger.Reconfigure(logger.FileConfigFor(&cur.Daemon)); err != nil {
+			for _, key := range logKeys {
+				fail(key, err)
+			}
+		} else {
+			s.mgr.SetLogConfig(&cur.Daemon)
+			result.Applied = append(result.Applied, logKeys...)
+		}
+	}
+
+	s.mu.Lock()
+	s.cfg = cur
+	s.mu.Unlock()
+
+	log := logger.Get()
+	if len(result.Applied) > 0 {
+		log.Infof("reload: applied %s", strings.Join(result.Applied, ", "))
+	}
+	if len(result.RestartRequired) > 0 {
+		log.Warnf("reload: %s will take effect when the daemon restarts", strings.Join(result.RestartRequired, ", "))
+	}
+	s.broadcastEvent(model.Event{
+		Type:      model.EventDaemonReloaded,
+		Message:   "daemon configuration reloaded",
+		Data:      result,
+		Timestamp: time.Now(),
+	})
+	return result, nil
+}
+
+// rebind serves the API on addr and shuts down the previous listener once
+// its pending requests are done.
+func (s *Server) rebind(addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	old := s.serve(ln)
+	if old != nil {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			_ = old.Shutdown(ctx)
+		}()
+	}
+	return nil
+}
+
+// isReloadSignal reports whether sig asks the daemon to reload its
+// configuration.
+func isReloadSignal(sig os.Signal) bool {
+	for _, s := range reloadSignals {
+		if sig == s {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,7 @@ package daemon
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -22,13 +23,19 @@ import (
 
 // Server is the daemon HTTP server that exposes the REST API and WebSocket.
 type Server struct {
+	mu        sync.Mutex // guards cfg and srv
 	cfg       *config.GlobalConfig
+	srv       *http.Server
+	reloadMu  sync.Mutex
 	loader    *config.Loader
 	mgr       *manager.Manager
 	router    *gin.Engine
 	wsClients map[*websocket.Conn]bool
 	wsMu      sync.Mutex
 	startedAt time.Time
+
+	shutdownCh   chan struct{} // closed by Shutdown
+	shutdownOnce sync.Once
 }
 
 // New creates a new daemon server.
@@ -48,6 +55,8 @@ func New(loader *config.Loader) *Server {
 		router:    router,
 		wsClients: make(map[*websocket.Conn]bool),
 		startedAt: time.Now(),
+
+		shutdownCh: make(chan struct{}),
 	}
 
 	// Register event handler for WebSocket broadcasting
@@ -59,6 +68,27 @@ func New(loader *config.Loader) *Server {
 	return s
 }
 
+// Shutdown makes Run stop all services and return, as on SIGINT or
+// SIGTERM. It does not wait for that to finish.
+func (s *Server) Shutdown() {
+	s.shutdownOnce.Do(func() { close(s.shutdownCh) })
+}
+
+// waitReload waits for the next signal. It reports true for a reload
+// signal, and false once the daemon should shut down.
+func (s *Server) waitReload(signals <-chan os.Signal) bool {
+	select {
+	case sig := <-signals:
+		if !isReloadSignal(sig) {
+			return false
+		}
+		logger.Get().Infof("received %s, reloading configuration", sig)
+		return true
+	case <-s.shutdownCh:
+		return false
+	}
+}
+
 // Run starts the HTTP server and blocks until shutdown.
 func (s *Server) Run() error {
 	log := logger.Get()
@@ -69,35 +99,32 @@ func (s *Server) Run() error {
 	}
 	defer s.removePIDFile()
 
-	// Load and auto-start services
-	if err := s.mgr.LoadAndStart(); err != nil {
-		log.Errorf("load and start services: %v", err)
-	}
-
 	s.mgr.OnEvent(func(event model.Event) {
 		if event.Type != model.EventServiceLog {
 			log.Infof("event: %s %s - %s", event.Type, event.Service, event.Message)
 		}
 	})
 
-	// Start HTTP server
-	srv := &http.Server{
-		Addr:    s.cfg.Daemon.Listen,
-		Handler: s.router,
+	// Load and auto-start services
+	if err := s.mgr.LoadAndStart(); err != nil {
+		return fmt.Errorf("load services: %w", err)
 	}
 
-	// Graceful shutdown on signal
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
-	go func() {
-		log.Infof("daemon listening on %s", s.cfg.Daemon.Listen)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("listen: %v", err)
+	// Start HTTP server
+	ln, err := net.Listen("tcp", s.cfg.Daemon.Listen)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	s.serve(ln)
+
+	// Graceful shutdown on signal or Shutdown, reload on the reload signals
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, append([]os.Signal{syscall.SIGINT, syscall.SIGTERM}, reloadSignals...)...)
+	for s.waitReload(signals) {
+		if _, err := s.Reload(); err != nil {
+			log.Errorf("reload: %v", err)
 		}
-	}()
-
-	<-quit
+	}
 	log.Info("shutting down daemon...")
 
 	// Stop all services
@@ -106,6 +133,9 @@ func (s *Server) Run() error {
 	// Shutdown HTTP server
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
+	s.mu.Lock()
+	srv := s.srv
+	s.mu.Unlock()
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Errorf("server shutdown: %v", err)
 	}
@@ -121,13 +151,41 @@ func (s *Server) Run() error {
 	return nil
 }
 
+// serve serves the API on ln in the background and returns the server it
+// replaces, if any.
+func (s *Server) serve(ln net.Listener) *http.Server {
+	srv := &http.Server{Handler: s.router}
+	go func() {
+		logger.Get().Infof("daemon listening on %s", ln.Addr())
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			logger.Get().Fatalf("listen: %v", err)
+		}
+	}()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old := s.srv
+	s.srv = srv
+	return old
+}
+
 func (s *Server) writePIDFile() error {
+	return writePIDFile(s.pidFile())
+}
+
+func writePIDFile(path string) error {
 	pid := os.Getpid()
-	return os.WriteFile(s.cfg.Daemon.PIDFile, []byte(strconv.Itoa(pid)), 0644)
+	return os.WriteFile(path, []byte(strconv.Itoa(pid)), 0644)
 }
 
 func (s *Server) removePIDFile() {
-	_ = os.Remove(s.cfg.Daemon.PIDFile)
+	_ = os.Remove(s.pidFile())
+}
+
+func (s *Server) pidFile() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg.Daemon.PIDFile
 }
 
 // WebSocket upgrader
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package daemon
+
+import (
+	"os"
+	"syscall"
+)
+
+// reloadSignals make the daemon reload its configuration.
+var reloadSignals = []os.Signal{syscall.SIGHUP}
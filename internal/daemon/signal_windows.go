@@ -0,0 +1,9 @@
+//go:build windows
+
+package daemon
+
+import "os"
+
+// reloadSignals make the daemon reload its configuration. Windows has no
+// reload signal; use the API instead.
+var reloadSignals []os.Signal
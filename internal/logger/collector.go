@@ -4,12 +4,10 @@ import (
 	"bufio"
 	"io"
 	"os"
-	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/BAIGUANGMEI/goser/internal/model"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // LogCallback is called for each log line collected from a service.
@@ -18,8 +16,7 @@ type LogCallback func(entry model.LogEntry)
 // Collector captures stdout/stderr from a service process and writes to log files.
 type Collector struct {
 	serviceName string
-	logDir      string
-	writer      *lumberjack.Logger
+	writer      *rotatingFile
 	callback    LogCallback
 	mu          sync.Mutex
 	lines       []model.LogEntry
@@ -27,23 +24,16 @@ type Collector struct {
 }
 
 // NewCollector creates a new log collector for a service.
-func NewCollector(serviceName, logDir string, callback LogCallback) *Collector {
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		Get().Errorf("failed to create log dir %s: %v", logDir, err)
+func NewCollector(serviceName string, files FileConfig, callback LogCallback) *Collector {
+	if err := os.MkdirAll(files.Dir, 0755); err != nil {
+		Get().Errorf("failed to create log dir %s: %v", files.Dir, err)
 	}
 
 	return &Collector{
 		serviceName: serviceName,
-		logDir:      logDir,
-		writer: &lumberjack.Logger{
-			Filename:   filepath.Join(logDir, serviceName+".log"),
-			MaxSize:    50, // MB
-			MaxBackups: 3,
-			MaxAge:     7,
-			Compress:   true,
-		},
-		callback: callback,
-		maxLines: 1000, // Keep last 1000 lines in memory
+		writer:      newRotatingFile(serviceName+".log", 3, files),
+		callback:    callback,
+		maxLines:    1000, // Keep last 1000 lines in memory
 	}
 }
 
@@ -100,6 +90,24 @@ func (c *Collector) GetLines(n int) []model.LogEntry {
 	return result
 }
 
+// Chown makes the log file owned by the given user and group, creating it
+// if needed. Rotated log files keep the owner of the file they replace.
+func (c *Collector) Chown(uid, gid int) error {
+	name := c.writer.Filename()
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	_ = f.Close()
+	return os.Chown(name, uid, gid)
+}
+
+// Reconfigure moves the log file or changes its rotation limits. Output
+// written so far stays in the previous file.
+func (c *Collector) Reconfigure(files FileConfig) error {
+	return c.writer.Reconfigure(files)
+}
+
 // Close closes the log writer.
 func (c *Collector) Close() error {
 	return c.writer.Close()
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"github.com/BAIGUANGMEI/goser/internal/config"
+)
+
+// FileConfig configures where log files are written and when they are
+// rotated.
+type FileConfig struct {
+	Dir        string
+	MaxSizeMB  int // size at which a file is rotated
+	MaxAgeDays int // days rotated files are kept, 0 keeps them
+}
+
+// FileConfigFor returns the log file configuration of the daemon.
+func FileConfigFor(cfg *config.DaemonConfig) FileConfig {
+	maxSize, maxAge := cfg.LogRotation()
+	return FileConfig{Dir: cfg.LogDir, MaxSizeMB: maxSize, MaxAgeDays: maxAge}
+}
+
+// rotatingFile is a rotated log file that can be moved to another directory
+// or given new rotation limits while it is written to.
+type rotatingFile struct {
+	mu      sync.Mutex
+	name    string // file name within the log directory
+	backups int
+	w       *lumberjack.Logger
+}
+
+func newRotatingFile(name string, backups int, files FileConfig) *rotatingFile {
+	f := &rotatingFile{name: name, backups: backups}
+	f.w = f.open(files)
+	return f
+}
+
+func (f *rotatingFile) open(files FileConfig) *lumberjack.Logger {
+	return &lumberjack.Logger{
+		Filename:   filepath.Join(files.Dir, f.name),
+		MaxSize:    files.MaxSizeMB,
+		MaxBackups: f.backups,
+		MaxAge:     files.MaxAgeDays,
+		Compress:   true,
+	}
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.w.Write(p)
+}
+
+// Filename returns the path of the current log file.
+func (f *rotatingFile) Filename() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.w.Filename
+}
+
+// Reconfigure closes the current file and continues writing according to
+// files. Files already written stay where they are.
+func (f *rotatingFile) Reconfigure(files FileConfig) error {
+	if err := os.MkdirAll(files.Dir, 0755); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	old := f.w
+	f.w = f.open(files)
+	return old.Close()
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.w.Close()
+}
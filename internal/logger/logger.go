@@ -2,18 +2,19 @@ package logger
 
 import (
 	"os"
-	"path/filepath"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-var globalLogger *zap.SugaredLogger
+var (
+	globalLogger *zap.SugaredLogger
+	daemonFile   *rotatingFile
+)
 
 // Init initializes the global logger with file and console output.
-func Init(logDir string) error {
-	if err := os.MkdirAll(logDir, 0755); err != nil {
+func Init(files FileConfig) error {
+	if err := os.MkdirAll(files.Dir, 0755); err != nil {
 		return err
 	}
 
@@ -27,17 +28,11 @@ func Init(logDir string) error {
 	fileEncoder := zapcore.NewJSONEncoder(fileEncoderCfg)
 
 	// File writer with rotation
-	fileWriter := &lumberjack.Logger{
-		Filename:   filepath.Join(logDir, "goserd.log"),
-		MaxSize:    50, // MB
-		MaxBackups: 5,
-		MaxAge:     7, // days
-		Compress:   true,
-	}
+	daemonFile = newRotatingFile("goserd.log", 5, files)
 
 	core := zapcore.NewTee(
 		zapcore.NewCore(consoleEncoder, zapcore.AddSync(os.Stdout), zapcore.DebugLevel),
-		zapcore.NewCore(fileEncoder, zapcore.AddSync(fileWriter), zapcore.InfoLevel),
+		zapcore.NewCore(fileEncoder, zapcore.AddSync(daemonFile), zapcore.InfoLevel),
 	)
 
 	logger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
@@ -45,6 +40,14 @@ func Init(logDir string) error {
 	return nil
 }
 
+// Reconfigure moves the daemon log file or changes its rotation limits.
+func Reconfigure(files FileConfig) error {
+	if daemonFile == nil {
+		return nil
+	}
+	return daemonFile.Reconfigure(files)
+}
+
 // Get returns the global sugared logger.
 func Get() *zap.SugaredLogger {
 	if globalLogger == nil {
@@ -0,0 +1,69 @@
+package manager
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/BAIGUANGMEI/goser/internal/config"
+	"github.com/BAIGUANGMEI/goser/internal/logger"
+)
+
+// restartDelay returns how long to wait before the given restart attempt
+// (starting at 1) according to the service's restart policy.
+func restartDelay(cfg *config.ServiceConfig, attempt int) time.Duration {
+	delay := cfg.RestartDelay
+	rp := cfg.RestartPolicy
+	if rp == nil {
+		return delay
+	}
+
+	if rp.Backoff == "exponential" {
+		for i := 1; i < attempt && delay < rp.MaxDelay; i++ {
+			delay *= 2
+		}
+		if delay > rp.MaxDelay {
+			delay = rp.MaxDelay
+		}
+	}
+
+	if rp.Jitter > 0 {
+		spread := float64(delay) * rp.Jitter
+		delay += time.Duration((rand.Float64()*2 - 1) * spread)
+	}
+	return delay
+}
+
+// resetWhenStable resets the restart counter once a process has stayed up
+// for the reset_after window of its restart policy. The window is looked up
+// again whenever it may have passed, so that changing it applies to the
+// running process.
+func (m *Manager) resetWhenStable(proc *Process, done <-chan struct{}) {
+	var window time.Duration
+	for {
+		wait := settingsPoll
+		if rp := proc.Config().RestartPolicy; rp != nil && rp.ResetAfter > 0 {
+			window = rp.ResetAfter
+			wait = time.Until(proc.StartedAt().Add(window))
+			if wait <= 0 {
+				break
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-done:
+			timer.Stop()
+			return
+		case <-m.stopCh:
+			timer.Stop()
+			return
+		}
+	}
+
+	if n := proc.RestartCount(); n > 0 {
+		logger.Get().Infof("monitor: %s stable for %s, resetting restart counter (was %d)",
+			proc.Config().Name, window, n)
+		proc.ResetRestartCount()
+	}
+}
@@ -0,0 +1,38 @@
+package manager
+
+import (
+	"os"
+	"os/exec"
+
+	"github.com/BAIGUANGMEI/goser/internal/config"
+)
+
+// prepareCommand sets up a command to run in a service's context: its
+// working directory, its environment plus extraEnv, and its user and group.
+// When the service runs as another user, HOME, USER and LOGNAME are set for
+// that user unless the service's env overrides them. It returns the
+// credentials the command runs with, or nil if they are unchanged.
+func prepareCommand(cmd *exec.Cmd, cfg *config.ServiceConfig, extraEnv ...string) (*config.Credentials, error) {
+	if cfg.WorkingDir != "" {
+		cmd.Dir = cfg.WorkingDir
+	}
+
+	cred, err := cfg.Credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd.Env = os.Environ()
+	if cred != nil && cred.Username != "" {
+		cmd.Env = append(cmd.Env, "HOME="+cred.HomeDir, "USER="+cred.Username, "LOGNAME="+cred.Username)
+	}
+	cmd.Env = append(cmd.Env, extraEnv...)
+	for k, v := range cfg.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+
+	if cred != nil {
+		setCredential(cmd, cred)
+	}
+	return cred, nil
+}
@@ -0,0 +1,287 @@
+package manager
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/BAIGUANGMEI/goser/internal/config"
+	"github.com/BAIGUANGMEI/goser/internal/logger"
+	"github.com/BAIGUANGMEI/goser/internal/model"
+)
+
+// readyPollInterval is how often a waiting service checks its dependencies.
+const readyPollInterval = 250 * time.Millisecond
+
+// stoppedDependencies returns the transitive dependencies of a service that
+// are neither active nor completed, in the order they need to be started.
+// A dependency on a multi-instance service is a dependency on each instance.
+func (m *Manager) stoppedDependencies(name string) []string {
+	var result []string
+	visited := map[string]bool{name: true}
+
+	var visit func(n string)
+	visit = func(n string) {
+		proc := m.getProcess(n)
+		if proc == nil {
+			return
+		}
+		for _, svc := range proc.Config().DependsOn {
+			for _, dep := range m.processNames(svc) {
+				if visited[dep] {
+					continue
+				}
+				visited[dep] = true
+				visit(dep)
+				if d := m.getProcess(dep); d != nil && !isActive(d.State()) && d.State() != model.StateCompleted {
+					result = append(result, dep)
+				}
+			}
+		}
+	}
+	visit(name)
+	return result
+}
+
+// dependents returns the services that directly depend on name. The
+// dependents of an instance are those of its multi-instance service.
+func (m *Manager) dependents(name string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	svc := serviceName(name)
+	var result []string
+	for n, proc := range m.processes {
+		for _, dep := range proc.Config().DependsOn {
+			if dep == name || dep == svc {
+				result = append(result, n)
+				break
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// activeDependents returns the transitive dependents of a service that are
+// active, ordered so that every service comes before its own dependencies.
+func (m *Manager) activeDependents(name string) []string {
+	var result []string
+	visited := map[string]bool{name: true}
+
+	var visit func(n string)
+	visit = func(n string) {
+		for _, d := range m.dependents(n) {
+			if visited[d] {
+				continue
+			}
+			visited[d] = true
+			visit(d)
+			if proc := m.getProcess(d); proc != nil && isUp(proc) {
+				result = append(result, d)
+			}
+		}
+	}
+	visit(name)
+	return result
+}
+
+// stopLevels groups all processes for shutdown. Each group only contains
+// services whose dependents are in earlier groups, so groups can be stopped
+// one after another with the services in a group stopped in parallel.
+func (m *Manager) stopLevels() [][]*Process {
+	services := m.loader.GetServices()
+	order := config.DependencyOrder(services)
+	level := make(map[string]int, len(order))
+	maxLevel := 0
+	for _, name := range order {
+		l := 0
+		for _, dep := range services[name].DependsOn {
+			if dl, ok := level[dep]; ok && dl+1 > l {
+				l = dl + 1
+			}
+		}
+		level[name] = l
+		if l > maxLevel {
+			maxLevel = l
+		}
+	}
+
+	// Services left out of the order (dependency cycles) are stopped first.
+	groups := make([][]*Process, maxLevel+2)
+	m.mu.RLock()
+	for name, proc := range m.processes {
+		idx := 0
+		if l, ok := level[serviceName(name)]; ok {
+			idx = maxLevel - l + 1
+		}
+		groups[idx] = append(groups[idx], proc)
+	}
+	m.mu.RUnlock()
+	return groups
+}
+
+// dependenciesReady reports whether all direct dependencies of a service are ready.
+func (m *Manager) dependenciesReady(proc *Process) bool {
+	for _, svc := range proc.Config().DependsOn {
+		for _, dep := range m.processNames(svc) {
+			d := m.getProcess(dep)
+			if d == nil || !isReady(d) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// startWhenReady waits for the direct dependencies of a waiting service to
+// become ready, then starts it. If a dependency fails or does not become
+// ready in time, the service is marked as failed.
+func (m *Manager) startWhenReady(proc *Process) {
+	log := logger.Get()
+	cfg := proc.Config()
+
+	for _, svc := range cfg.DependsOn {
+		for _, dep := range m.processNames(svc) {
+			if err := m.waitReady(dep); err != nil {
+				if proc.State() != model.StateWaiting {
+					return
+				}
+				msg := fmt.Sprintf("dependency %s not ready: %v", dep, err)
+				log.Errorf("failed to start %s: %s", cfg.Name, msg)
+				proc.setFailed(msg)
+				m.emitEvent(model.Event{
+					Type:      model.EventServiceFailed,
+					Service:   cfg.Name,
+					Message:   msg,
+					Timestamp: time.Now(),
+				})
+				return
+			}
+		}
+	}
+
+	// The wait may have been cancelled by a stop request.
+	if proc.State() != model.StateWaiting {
+		return
+	}
+	if err := m.startProcess(proc); err != nil {
+		log.Errorf("failed to start %s: %v", cfg.Name, err)
+		m.emitEvent(model.Event{
+			Type:      model.EventServiceFailed,
+			Service:   cfg.Name,
+			Message:   "start failed: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+// waitReady blocks until the named service is ready. The service's
+// ready_timeout starts counting once its process is running; a oneshot
+// service is waited for until it has completed, however long it runs.
+func (m *Manager) waitReady(name string) error {
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+
+	var deadline time.Time
+	for {
+		proc := m.getProcess(name)
+		if proc == nil {
+			return fmt.Errorf("service %s not found", name)
+		}
+
+		switch state := proc.State(); state {
+		case model.StateRunning:
+			if isReady(proc) {
+				return nil
+			}
+			if proc.Config().IsOneshot() {
+				break
+			}
+			if deadline.IsZero() {
+				deadline = time.Now().Add(proc.Config().ReadyTimeout)
+			} else if time.Now().After(deadline) {
+				return fmt.Errorf("not healthy after %s", proc.Config().ReadyTimeout)
+			}
+		case model.StateCompleted:
+			return nil
+		case model.StateWaiting, model.StateStarting, model.StateBackoff:
+		default:
+			return fmt.Errorf("service is %s", state)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-m.stopCh:
+			return fmt.Errorf("daemon is shutting down")
+		}
+	}
+}
+
+// isActive reports whether a service is running or on its way to running,
+// including a pending automatic restart.
+func isActive(state model.ServiceState) bool {
+	switch state {
+	case model.StateRunning, model.StateStarting, model.StateWaiting, model.StateBackoff:
+		return true
+	}
+	return false
+}
+
+// isUp reports whether a service counts as started: it is active, or it is
+// a completed oneshot service with remain_after_exit.
+func isUp(proc *Process) bool {
+	return isActive(proc.State()) || proc.RemainsCompleted()
+}
+
+// isReady reports whether a service can be relied on by its dependents:
+// a oneshot service has completed, any other service is running and, if it
+// has a health check, healthy.
+func isReady(proc *Process) bool {
+	if proc.Config().IsOneshot() {
+		return proc.State() == model.StateCompleted
+	}
+	if proc.State() != model.StateRunning {
+		return false
+	}
+	if proc.Config().HealthCheck == nil {
+		return true
+	}
+	return proc.Health() == model.HealthHealthy
+}
+
+// DependencyGraph returns the dependency graph of all services, annotated
+// with their current state.
+func (m *Manager) DependencyGraph() *model.DependencyGraph {
+	services := m.loader.GetServices()
+	missing := config.MissingDependencies(services)
+
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	graph := &model.DependencyGraph{
+		Nodes:  make([]model.GraphNode, 0, len(names)),
+		Order:  config.DependencyOrder(services),
+		Cycles: config.DependencyCycles(services),
+	}
+	for _, name := range names {
+		node := model.GraphNode{
+			Name:    name,
+			State:   model.StateStopped,
+			Missing: missing[name],
+		}
+		for _, dep := range services[name].DependsOn {
+			if _, ok := services[dep]; ok {
+				node.DependsOn = append(node.DependsOn, dep)
+			}
+		}
+		if proc := m.getProcess(name); proc != nil {
+			node.State = proc.State()
+		}
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	return graph
+}
@@ -0,0 +1,91 @@
+package manager
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/BAIGUANGMEI/goser/internal/logger"
+	"github.com/BAIGUANGMEI/goser/internal/model"
+)
+
+// desiredStates persists the state each service was last put in by a
+// start or stop request, so it can be restored when the daemon starts.
+type desiredStates struct {
+	mu     sync.Mutex
+	path   string
+	states map[string]model.DesiredState
+}
+
+// loadDesiredStates reads the desired states stored at path. A missing or
+// unreadable file yields an empty set.
+func loadDesiredStates(path string) *desiredStates {
+	d := &desiredStates{path: path, states: make(map[string]model.DesiredState)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return d
+	}
+	if err := json.Unmarshal(data, &d.states); err != nil {
+		logger.Get().Warnf("ignoring invalid desired state file %s: %v", path, err)
+		d.states = make(map[string]model.DesiredState)
+	}
+	return d
+}
+
+// get returns the recorded desired state of a service.
+func (d *desiredStates) get(name string) (model.DesiredState, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	state, ok := d.states[name]
+	return state, ok
+}
+
+// set records the desired state of a service.
+func (d *desiredStates) set(name string, state model.DesiredState) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.states[name] == state {
+		return
+	}
+	d.states[name] = state
+	d.save()
+}
+
+// remove forgets the desired state of a service.
+func (d *desiredStates) remove(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.states[name]; !ok {
+		return
+	}
+	delete(d.states, name)
+	d.save()
+}
+
+// save writes the desired states to disk. The caller must hold d.mu.
+func (d *desiredStates) save() {
+	data, err := json.MarshalIndent(d.states, "", "  ")
+	if err == nil {
+		tmp := d.path + ".tmp"
+		if err = os.WriteFile(tmp, data, 0600); err == nil {
+			err = os.Rename(tmp, d.path)
+		}
+	}
+	if err != nil {
+		logger.Get().Warnf("failed to save desired service states: %v", err)
+	}
+}
+
+// desiredState returns the state a service should be in when the daemon
+// starts: the state of the last persisted start or stop request, or
+// according to auto_start if there was none.
+func (m *Manager) desiredState(proc *Process) model.DesiredState {
+	cfg := proc.Config()
+	if state, ok := m.desired.get(cfg.Name); ok {
+		return state
+	}
+	if cfg.AutoStart {
+		return model.DesiredStarted
+	}
+	return model.DesiredStopped
+}
@@ -0,0 +1,42 @@
+package manager
+
+import (
+	"errors"
+	"io"
+	"os"
+)
+
+// preExec is the exec shim a process was started through, see setPreExec.
+// The shim reports a failure to set up the process on an error pipe, which
+// is closed without output once it has executed the service's command.
+type preExec struct {
+	r, w *os.File
+}
+
+// started waits until the shim has executed the service's command, and
+// returns the error it reported if it could not. It is a no-op for a
+// process started without a shim.
+func (s *preExec) started() error {
+	if s == nil {
+		return nil
+	}
+	_ = s.w.Close()
+	defer s.r.Close()
+	msg, err := io.ReadAll(s.r)
+	if err != nil {
+		return err
+	}
+	if len(msg) > 0 {
+		return errors.New(string(msg))
+	}
+	return nil
+}
+
+// close releases the error pipe of a process that could not be started.
+func (s *preExec) close() {
+	if s == nil {
+		return
+	}
+	_ = s.w.Close()
+	_ = s.r.Close()
+}
@@ -0,0 +1,137 @@
+//go:build !windows
+
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/BAIGUANGMEI/goser/internal/config"
+)
+
+// Settings that must be in place before a service's command runs, and that
+// os/exec cannot apply in the child, are applied by an exec shim: the
+// daemon executes itself under the name execShimName, and the shim sets the
+// resource limits and umask, switches to the service's user and group, and
+// then executes the command. Everything the command starts runs with the
+// settings, and the daemon's own umask is never changed.
+
+// execShimName is argv[0] of the exec shim.
+const execShimName = "goser-exec"
+
+// execShimErrFd is the file descriptor of the shim's error pipe.
+const execShimErrFd = 3
+
+// execSpec is what the exec shim sets up, passed to it as argv[1].
+type execSpec struct {
+	Rlimits    []config.Rlimit     `json:"rlimits,omitempty"`
+	Umask      *int                `json:"umask,omitempty"`
+	Credential *syscall.Credential `json:"credential,omitempty"`
+	Pdeathsig  syscall.Signal      `json:"pdeathsig,omitempty"`
+}
+
+func init() {
+	if len(os.Args) > 2 && os.Args[0] == execShimName {
+		runExecShim()
+	}
+}
+
+// setPreExec makes the command run through the exec shim if the service
+// has resource limits or a umask. Call started on the result once the
+// command has been started. The command's user and group are switched by
+// the shim after setting the limits, so that an unprivileged service can be
+// given limits above its own hard limits.
+func setPreExec(cmd *exec.Cmd, cfg *config.ServiceConfig) (*preExec, error) {
+	var spec execSpec
+	if rlimitsSupported && cfg.Limits != nil {
+		spec.Rlimits = cfg.Limits.Rlimits()
+	}
+	if cfg.Umask != "" {
+		mask, _ := cfg.UmaskValue()
+		spec.Umask = &mask
+	}
+	if len(spec.Rlimits) == 0 && spec.Umask == nil {
+		return nil, nil
+	}
+	if cmd.Err != nil {
+		// Let cmd.Start report that the command was not found
+		return nil, nil
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("exec shim: %w", err)
+	}
+	if attr := cmd.SysProcAttr; attr != nil && attr.Credential != nil {
+		spec.Credential, attr.Credential = attr.Credential, nil
+		spec.Pdeathsig = parentDeathSignal(cmd)
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("exec shim: %w", err)
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("exec shim: %w", err)
+	}
+
+	cmd.Args = append([]string{execShimName, string(data), cmd.Path}, cmd.Args...)
+	cmd.Path = self
+	cmd.ExtraFiles = []*os.File{w}
+	return &preExec{r: r, w: w}, nil
+}
+
+// runExecShim sets up the process as described by argv[1] and executes the
+// command in argv[2] with the arguments that follow. It does not return.
+func runExecShim() {
+	syscall.CloseOnExec(execShimErrFd)
+	fail := func(err error) {
+		errPipe := os.NewFile(execShimErrFd, "exec shim")
+		_, _ = errPipe.WriteString(err.Error())
+		os.Exit(127)
+	}
+
+	var spec execSpec
+	if err := json.Unmarshal([]byte(os.Args[1]), &spec); err != nil {
+		fail(fmt.Errorf("exec shim: %w", err))
+	}
+	if err := setRlimits(spec.Rlimits); err != nil {
+		fail(err)
+	}
+	if spec.Umask != nil {
+		syscall.Umask(*spec.Umask)
+	}
+	if c := spec.Credential; c != nil {
+		ppid := os.Getppid()
+		if err := syscall.Setgroups(intIDs(c.Groups)); err != nil {
+			fail(fmt.Errorf("set groups: %w", err))
+		}
+		if err := syscall.Setgid(int(c.Gid)); err != nil {
+			fail(fmt.Errorf("set gid: %w", err))
+		}
+		if err := syscall.Setuid(int(c.Uid)); err != nil {
+			fail(fmt.Errorf("set uid: %w", err))
+		}
+		// Changing credentials clears the parent death signal
+		if spec.Pdeathsig != 0 {
+			if err := restoreParentDeathSignal(spec.Pdeathsig, ppid); err != nil {
+				fail(err)
+			}
+		}
+	}
+
+	path := os.Args[2]
+	err := syscall.Exec(path, os.Args[3:], os.Environ())
+	fail(fmt.Errorf("exec %s: %w", path, err))
+}
+
+func intIDs(ids []uint32) []int {
+	result := make([]int, len(ids))
+	for i, id := range ids {
+		result[i] = int(id)
+	}
+	return result
+}
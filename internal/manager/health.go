@@ -0,0 +1,268 @@
+package manager
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/BAIGUANGMEI/goser/internal/config"
+	"github.com/BAIGUANGMEI/goser/internal/logger"
+	"github.com/BAIGUANGMEI/goser/internal/model"
+)
+
+const (
+	// maxHealthResults is the number of recent probe results kept per service.
+	maxHealthResults = 5
+	// maxProbeOutput caps the probe output stored with each result.
+	maxProbeOutput = 512
+)
+
+// healthState tracks the health check results of a single process run.
+type healthState struct {
+	status      model.HealthStatus
+	failures    int
+	successes   int
+	lastOutput  string
+	lastCheckAt *time.Time
+	results     []model.HealthResult
+}
+
+func newHealthState() healthState {
+	return healthState{status: model.HealthUnknown}
+}
+
+// record applies a probe result and returns the resulting health status.
+// Failures during the start period are recorded but not counted.
+func (h *healthState) record(result model.HealthResult, hc *config.HealthCheckConfig, inStartPeriod bool) model.HealthStatus {
+	ts := result.Timestamp
+	h.lastCheckAt = &ts
+	h.lastOutput = result.Output
+	h.results = append(h.results, result)
+	if len(h.results) > maxHealthResults {
+		h.results = h.results[len(h.results)-maxHealthResults:]
+	}
+
+	if result.Healthy {
+		h.successes++
+		h.failures = 0
+		if h.successes >= hc.SuccessThreshold {
+			h.status = model.HealthHealthy
+		}
+	} else if !inStartPeriod {
+		h.failures++
+		h.successes = 0
+		if h.failures >= hc.FailureThreshold {
+			h.status = model.HealthUnhealthy
+		}
+	}
+	return h.status
+}
+
+func (h *healthState) info() *model.HealthInfo {
+	results := make([]model.HealthResult, len(h.results))
+	copy(results, h.results)
+	return &model.HealthInfo{
+		Status:               h.status,
+		ConsecutiveFailures:  h.failures,
+		ConsecutiveSuccesses: h.successes,
+		LastOutput:           h.lastOutput,
+		LastCheckAt:          h.lastCheckAt,
+		Results:              results,
+	}
+}
+
+// healthCheck periodically probes a running process until it exits. The
+// health check is looked up again before every probe, so that changing,
+// adding or removing it applies to the running process.
+func (m *Manager) healthCheck(proc *Process, done <-chan struct{}) {
+	var interval time.Duration
+	ticker := time.NewTicker(settingsPoll)
+	defer ticker.Stop()
+
+	for {
+		// Probe at the configured interval, or look for a health check
+		// being added while there is none.
+		want := settingsPoll
+		if hc := proc.Config().HealthCheck; hc != nil {
+			want = hc.Interval
+		}
+		if want != interval {
+			ticker.Reset(want)
+			interval = want
+		}
+
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		case <-m.stopCh:
+			return
+		}
+
+		cfg := proc.Config()
+		hc := cfg.HealthCheck
+		if hc == nil {
+			proc.resetHealth()
+			continue
+		}
+
+		result := probe(cfg, hc)
+		prev, cur := proc.recordHealth(result, hc)
+		if prev == cur {
+			continue
+		}
+
+		eventType := model.EventServiceHealthy
+		if cur == model.HealthUnhealthy {
+			eventType = model.EventServiceUnhealthy
+			logger.Get().Warnf("health: %s is unhealthy: %s", cfg.Name, result.Output)
+		} else {
+			logger.Get().Infof("health: %s is healthy", cfg.Name)
+		}
+		m.emitEvent(model.Event{
+			Type:      eventType,
+			Service:   cfg.Name,
+			Message:   result.Output,
+			Data:      result,
+			Timestamp: result.Timestamp,
+		})
+
+		if cur == model.HealthUnhealthy {
+			m.onUnhealthy(proc, hc)
+			if hc.OnUnhealthy != "none" {
+				return
+			}
+		}
+	}
+}
+
+// onUnhealthy applies the service's on_unhealthy policy.
+func (m *Manager) onUnhealthy(proc *Process, hc *config.HealthCheckConfig) {
+	log := logger.Get()
+	name := proc.Config().Name
+
+	switch hc.OnUnhealthy {
+	case "restart":
+		// The monitor sees the exit and restarts the service,
+		// counting the attempt against max_restarts.
+		log.Warnf("health: terminating unhealthy service %s for restart", name)
+		if err := proc.Terminate(restartReasonUnhealthy); err != nil {
+			log.Errorf("health: failed to terminate %s: %v", name, err)
+		}
+	case "stop":
+		log.Warnf("health: stopping unhealthy service %s", name)
+		if err := proc.Stop(); err != nil {
+			log.Errorf("health: failed to stop %s: %v", name, err)
+			return
+		}
+		m.emitStopped(proc, "service stopped because it is unhealthy")
+	}
+}
+
+// probe runs a single health check against a service.
+func probe(cfg *config.ServiceConfig, hc *config.HealthCheckConfig) model.HealthResult {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), hc.Timeout)
+	defer cancel()
+
+	var healthy bool
+	var output string
+	switch hc.Type {
+	case "http":
+		healthy, output = probeHTTP(ctx, hc.Endpoint)
+	case "tcp":
+		healthy, output = probeTCP(ctx, hc.Endpoint)
+	case "command":
+		healthy, output = probeCommand(ctx, cfg, hc.Command)
+	default:
+		output = "unknown health check type: " + hc.Type
+	}
+
+	if len(output) > maxProbeOutput {
+		output = output[:maxProbeOutput] + "..."
+	}
+	return model.HealthResult{
+		Healthy:   healthy,
+		Output:    output,
+		Duration:  time.Since(start).Round(time.Millisecond).String(),
+		Timestamp: start,
+	}
+}
+
+func probeHTTP(ctx context.Context, endpoint string) (bool, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return false, err.Error()
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, err.Error()
+	}
+	defer resp.Body.Close()
+
+	healthy := resp.StatusCode >= 200 && resp.StatusCode < 400
+	return healthy, "HTTP " + resp.Status
+}
+
+func probeTCP(ctx context.Context, addr string) (bool, string) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return false, err.Error()
+	}
+	_ = conn.Close()
+	return true, "connected to " + addr
+}
+
+func probeCommand(ctx context.Context, cfg *config.ServiceConfig, command string) (bool, string) {
+	cmd := shellCommand(ctx, command)
+	if _, err := prepareCommand(cmd, cfg); err != nil {
+		return false, err.Error()
+	}
+
+	out, err := cmd.CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if ctx.Err() == context.DeadlineExceeded {
+		return false, "timed out: " + output
+	}
+	if err != nil {
+		if output == "" {
+			output = err.Error()
+		}
+		return false, output
+	}
+	if output == "" {
+		output = fmt.Sprintf("%q exited with code 0", command)
+	}
+	return true, output
+}
+
+// shellWaitDelay is how long the output of a shell command is still read
+// after it was killed because ctx is done, in case a process it started
+// escaped the kill and keeps the output open.
+const shellWaitDelay = time.Second
+
+// shellCommand builds a command that runs a command line through the
+// system shell. The command runs in its own process group, which is killed
+// as a whole when ctx is done.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	setProcAttr(cmd)
+	cmd.Cancel = func() error {
+		g := newProcGroup(cmd.Process.Pid)
+		defer g.release()
+		return g.kill("group")
+	}
+	cmd.WaitDelay = shellWaitDelay
+	return cmd
+}
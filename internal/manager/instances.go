@@ -0,0 +1,123 @@
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BAIGUANGMEI/goser/internal/config"
+	"github.com/BAIGUANGMEI/goser/internal/logger"
+	"github.com/BAIGUANGMEI/goser/internal/model"
+)
+
+// instances returns the process names of a multi-instance service, or nil
+// if name is not a multi-instance service.
+func (m *Manager) instances(name string) []string {
+	svc, ok := m.loader.GetService(name)
+	if !ok || !svc.IsMultiInstance() {
+		return nil
+	}
+	return processNamesOf(svc)
+}
+
+// processNames returns the names of the processes of a service: its
+// instances for a multi-instance service, otherwise the service itself.
+func (m *Manager) processNames(name string) []string {
+	if names := m.instances(name); names != nil {
+		return names
+	}
+	return []string{name}
+}
+
+// processNamesOf returns the names of the processes of svc.
+func processNamesOf(svc *config.ServiceConfig) []string {
+	if !svc.IsMultiInstance() {
+		return []string{svc.Name}
+	}
+	names := make([]string, svc.Instances)
+	for i := range names {
+		names[i] = config.InstanceName(svc.Name, i)
+	}
+	return names
+}
+
+// serviceName returns the name of the service a process belongs to.
+func serviceName(process string) string {
+	name, _, _ := config.SplitInstanceName(process)
+	return name
+}
+
+// registerInstance registers a process for instance i of a multi-instance
+// service.
+func (m *Manager) registerInstance(svc *config.ServiceConfig, i int) {
+	inst, err := svc.ForInstance(i)
+	if err != nil {
+		logger.Get().Errorf("failed to configure instance %d of %s: %v", i, svc.Name, err)
+		return
+	}
+	m.registerProcess(inst)
+}
+
+// ScaleService changes the number of instances of a multi-instance service.
+// Added instances are started if any instance of the service is started;
+// surplus instances are stopped and removed, highest numbers first.
+func (m *Manager) ScaleService(name string, instances int) error {
+	svc, ok := m.loader.GetService(name)
+	if !ok {
+		return fmt.Errorf("service %s not found", name)
+	}
+	if !svc.IsMultiInstance() {
+		return fmt.Errorf("service %s is not a multi-instance service (set instances in its configuration)", name)
+	}
+	if instances < 1 {
+		return fmt.Errorf("a multi-instance service needs at least 1 instance")
+	}
+
+	scaled := *svc
+	scaled.Instances = instances
+	if err := m.loader.SaveService(&scaled); err != nil {
+		return err
+	}
+	m.resizeInstances(svc, &scaled)
+
+	m.emitEvent(model.Event{
+		Type:      model.EventServiceScaled,
+		Service:   name,
+		Message:   fmt.Sprintf("service scaled from %d to %d instances", svc.Instances, instances),
+		Data:      map[string]interface{}{"instances": instances, "previous": svc.Instances},
+		Timestamp: time.Now(),
+	})
+	return nil
+}
+
+// resizeInstances registers the instances added to a multi-instance service
+// and removes those beyond its new instance count. Added instances are
+// started if any of the existing instances is started.
+func (m *Manager) resizeInstances(old, svc *config.ServiceConfig) {
+	log := logger.Get()
+
+	start := false
+	for i := 0; i < old.Instances && i < svc.Instances; i++ {
+		if proc := m.getProcess(config.InstanceName(svc.Name, i)); proc != nil && isUp(proc) {
+			start = true
+			break
+		}
+	}
+
+	for i := old.Instances; i < svc.Instances; i++ {
+		m.registerInstance(svc, i)
+		if !start {
+			continue
+		}
+		name := config.InstanceName(svc.Name, i)
+		if err := m.StartService(name, model.StartOptions{WithDependencies: true}); err != nil {
+			log.Errorf("failed to start %s: %v", name, err)
+		}
+	}
+
+	for i := old.Instances - 1; i >= svc.Instances; i-- {
+		name := config.InstanceName(svc.Name, i)
+		if err := m.removeProcess(name); err != nil {
+			log.Errorf("failed to remove %s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package manager
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/BAIGUANGMEI/goser/internal/config"
+	"github.com/BAIGUANGMEI/goser/internal/model"
+)
+
+var rlimitResources = map[string]int{
+	"nofile": unix.RLIMIT_NOFILE,
+	"nproc":  unix.RLIMIT_NPROC,
+	"core":   unix.RLIMIT_CORE,
+	"as":     unix.RLIMIT_AS,
+	"data":   unix.RLIMIT_DATA,
+	"cpu":    unix.RLIMIT_CPU,
+}
+
+// rlimitsSupported reports whether resource limits are applied on this
+// platform.
+const rlimitsSupported = true
+
+// setRlimits sets resource limits on the calling process, the exec shim of
+// a service. Soft and hard limits are set to the same value, except for the
+// CPU limit whose hard limit is one second higher so that the process
+// receives SIGXCPU before the kernel sends SIGKILL.
+func setRlimits(limits []config.Rlimit) error {
+	for _, l := range limits {
+		rl := unix.Rlimit{Cur: l.Value, Max: l.Value}
+		if l.Name == "cpu" {
+			rl.Max++
+		}
+		if err := unix.Setrlimit(rlimitResources[l.Name], &rl); err != nil {
+			return fmt.Errorf("set %s limit: %w", l.Name, err)
+		}
+	}
+	return nil
+}
+
+// readLimits returns the limits in effect for the configured resources.
+func readLimits(pid int, limits *config.LimitsConfig) []model.ResourceLimit {
+	var result []model.ResourceLimit
+	for _, l := range limits.Rlimits() {
+		var rl unix.Rlimit
+		if err := unix.Prlimit(pid, rlimitResources[l.Name], nil, &rl); err != nil {
+			continue
+		}
+		result = append(result, model.ResourceLimit{
+			Name: l.Name,
+			Soft: limitValue(rl.Cur),
+			Hard: limitValue(rl.Max),
+		})
+	}
+	return result
+}
+
+func limitValue(v uint64) int64 {
+	if v == unix.RLIM_INFINITY {
+		return -1
+	}
+	return int64(v)
+}
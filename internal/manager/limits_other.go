@@ -0,0 +1,23 @@
+//go:build !linux
+
+package manager
+
+import (
+	"github.com/BAIGUANGMEI/goser/internal/config"
+	"github.com/BAIGUANGMEI/goser/internal/model"
+)
+
+// rlimitsSupported reports whether resource limits are applied on this
+// platform. They are only implemented on Linux; configured limits are
+// ignored.
+const rlimitsSupported = false
+
+// setRlimits is only implemented on Linux.
+func setRlimits(limits []config.Rlimit) error {
+	return nil
+}
+
+// readLimits is only implemented on Linux.
+func readLimits(pid int, limits *config.LimitsConfig) []model.ResourceLimit {
+	return nil
+}
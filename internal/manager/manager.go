@@ -2,6 +2,7 @@ package manager
 
 import (
 	"fmt"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -15,24 +16,36 @@ type EventHandler func(event model.Event)
 
 // Manager orchestrates all managed service processes.
 type Manager struct {
-	mu            sync.RWMutex
-	processes     map[string]*Process
-	collectors    map[string]*logger.Collector
-	loader        *config.Loader
-	logDir        string
-	eventHandlers []EventHandler
-	stopCh        chan struct{}
+	mu                sync.RWMutex
+	processes         map[string]*Process
+	collectors        map[string]*logger.Collector
+	loader            *config.Loader
+	logFiles          logger.FileConfig
+	stateDir          string
+	desired           *desiredStates
+	statsInterval     time.Duration
+	configWatch       time.Duration
+	metricsResolution time.Duration
+	metricsRetention  time.Duration
+	eventHandlers     []EventHandler
+	stopCh            chan struct{}
 }
 
 // New creates a new process manager.
 func New(loader *config.Loader) *Manager {
 	globalCfg := loader.GetGlobal()
 	return &Manager{
-		processes:  make(map[string]*Process),
-		collectors: make(map[string]*logger.Collector),
-		loader:     loader,
-		logDir:     globalCfg.Daemon.LogDir,
-		stopCh:     make(chan struct{}),
+		processes:         make(map[string]*Process),
+		collectors:        make(map[string]*logger.Collector),
+		loader:            loader,
+		logFiles:          logger.FileConfigFor(&globalCfg.Daemon),
+		stateDir:          config.StateDir(),
+		desired:           loadDesiredStates(filepath.Join(config.GoserHome(), "desired_state.json")),
+		statsInterval:     globalCfg.Daemon.StatsInterval,
+		configWatch:       globalCfg.Daemon.ConfigWatchInterval,
+		metricsResolution: globalCfg.Daemon.MetricsResolution,
+		metricsRetention:  globalCfg.Daemon.MetricsRetention,
+		stopCh:            make(chan struct{}),
 	}
 }
 
@@ -54,23 +67,51 @@ func (m *Manager) emitEvent(event model.Event) {
 	}
 }
 
-// LoadAndStart loads all service configs and starts services with auto_start=true.
+// LoadAndStart loads all service configs, recovers processes left running
+// by a previous daemon and starts the services whose desired state is
+// started: those last started through StartService, or with auto_start=true
+// if they have not been started or stopped since. Scheduled services are
+// only started by the scheduler. Each instance of a multi-instance service
+// is handled on its own. A service file that fails to load is reported with
+// a service.config_error event and its service is left out; an error is
+// only returned if the services directory cannot be read.
 func (m *Manager) LoadAndStart() error {
+	errs, err := m.loader.LoadServices()
+	if err != nil {
+		return err
+	}
+	m.reportFileErrors(errs)
+
 	services := m.loader.GetServices()
 	for _, svc := range services {
 		m.registerService(svc)
 	}
+	m.recoverOrphans()
+
+	if m.statsInterval > 0 {
+		go m.sampleUsage(m.statsInterval)
+	}
+	go m.runScheduler()
+	if m.configWatch > 0 {
+		go m.watchServices(m.configWatch)
+	}
 
-	// Start services with auto_start, respecting dependencies
+	// Start services with auto_start, respecting dependencies. Dependents
+	// wait in the background until their dependencies are ready.
 	order := m.resolveDependencies()
-	for _, name := range order {
-		proc := m.getProcess(name)
-		if proc == nil {
-			continue
-		}
-		if proc.Config().AutoStart {
-			if err := m.StartService(name); err != nil {
-				logger.Get().Errorf("failed to auto-start %s: %v", name, err)
+	for _, svc := range order {
+		for _, name := range m.processNames(svc) {
+			proc := m.getProcess(name)
+			if proc == nil {
+				continue
+			}
+			if proc.Config().Schedule != "" {
+				continue
+			}
+			if m.desiredState(proc) == model.DesiredStarted && !isUp(proc) {
+				if err := m.StartService(name, model.StartOptions{WithDependencies: true, Transient: true}); err != nil {
+					logger.Get().Errorf("failed to auto-start %s: %v", name, err)
+				}
 			}
 		}
 	}
@@ -78,11 +119,23 @@ func (m *Manager) LoadAndStart() error {
 	return nil
 }
 
+// registerService registers the process of a service, or one process per
+// instance of a multi-instance service.
 func (m *Manager) registerService(svc *config.ServiceConfig) {
+	if !svc.IsMultiInstance() {
+		m.registerProcess(svc)
+		return
+	}
+	for i := 0; i < svc.Instances; i++ {
+		m.registerInstance(svc, i)
+	}
+}
+
+func (m *Manager) registerProcess(svc *config.ServiceConfig) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	collector := logger.NewCollector(svc.Name, m.logDir, func(entry model.LogEntry) {
+	collector := logger.NewCollector(svc.Name, m.logFiles, func(entry model.LogEntry) {
 		m.emitEvent(model.Event{
 			Type:      model.EventServiceLog,
 			Service:   svc.Name,
@@ -92,23 +145,100 @@ func (m *Manager) registerService(svc *config.ServiceConfig) {
 		})
 	})
 
-	proc := NewProcess(svc, collector)
+	proc := NewProcess(svc, collector, m.stateDir)
 	m.processes[svc.Name] = proc
 	m.collectors[svc.Name] = collector
 }
 
-// StartService starts a service by name.
-func (m *Manager) StartService(name string) error {
+// SetLogConfig moves the service log files to the log directory of cfg and
+// applies its rotation limits, both to services already registered and to
+// those registered later.
+func (m *Manager) SetLogConfig(cfg *config.DaemonConfig) {
+	files := logger.FileConfigFor(cfg)
+
+	m.mu.Lock()
+	m.logFiles = files
+	collectors := make(map[string]*logger.Collector, len(m.collectors))
+	for name, c := range m.collectors {
+		collectors[name] = c
+	}
+	m.mu.Unlock()
+
+	for name, c := range collectors {
+		if err := c.Reconfigure(files); err != nil {
+			logger.Get().Errorf("failed to reconfigure log file of %s: %v", name, err)
+		}
+	}
+}
+
+// StartService starts a service by name. If the service has dependencies
+// that are not running, a *model.DependencyError is returned unless
+// opts.WithDependencies is set, in which case the dependencies are started
+// first. While dependencies are becoming ready the service is in the
+// waiting state and is started in the background. Unless opts.Transient is
+// set, the service and the dependencies started for it are recorded as
+// started, to be started again when the daemon restarts. For a
+// multi-instance service, every instance that is not started is started.
+func (m *Manager) StartService(name string, opts model.StartOptions) error {
+	if instances := m.instances(name); instances != nil {
+		for _, inst := range instances {
+			if proc := m.getProcess(inst); proc != nil && isUp(proc) {
+				continue
+			}
+			if err := m.StartService(inst, opts); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := m.startService(name, opts); err != nil {
+		return err
+	}
+	if !opts.Transient {
+		m.desired.set(name, model.DesiredStarted)
+	}
+	return nil
+}
+
+func (m *Manager) startService(name string, opts model.StartOptions) error {
 	proc := m.getProcess(name)
 	if proc == nil {
 		return fmt.Errorf("service %s not found", name)
 	}
 
-	if err := proc.Start(); err != nil {
+	stopped := m.stoppedDependencies(name)
+	if len(stopped) > 0 && !opts.WithDependencies {
+		return &model.DependencyError{Service: name, Dependencies: stopped}
+	}
+	if len(stopped) == 0 && m.dependenciesReady(proc) {
+		return m.startProcess(proc)
+	}
+
+	if err := proc.SetWaiting(); err != nil {
 		return err
 	}
+	logger.Get().Infof("service %s is waiting for dependencies", name)
+
+	for _, dep := range stopped {
+		d := m.getProcess(dep)
+		if d == nil || isActive(d.State()) {
+			continue
+		}
+		if err := m.StartService(dep, model.StartOptions{WithDependencies: true, Transient: opts.Transient}); err != nil {
+			logger.Get().Errorf("failed to start dependency %s of %s: %v", dep, name, err)
+		}
+	}
+
+	go m.startWhenReady(proc)
+	return nil
+}
 
-	proc.ResetRestartCount()
+// startProcess launches a service's process and its monitor.
+func (m *Manager) startProcess(proc *Process) error {
+	name := proc.Config().Name
+	if err := proc.Start(); err != nil {
+		return err
+	}
 
 	m.emitEvent(model.Event{
 		Type:      model.EventServiceStarted,
@@ -123,29 +253,100 @@ func (m *Manager) StartService(name string) error {
 	return nil
 }
 
-// StopService stops a running service.
-func (m *Manager) StopService(name string) error {
+// StopService stops a running service. If other active services depend on
+// it, a *model.DependentsError is returned unless opts.Cascade is set, in
+// which case the dependents are stopped first. Unless opts.Transient is set,
+// the stopped services are recorded as stopped and stay stopped when the
+// daemon restarts. For a multi-instance service, every started instance is
+// stopped.
+func (m *Manager) StopService(name string, opts model.StopOptions) error {
+	if instances := m.instances(name); instances != nil {
+		for _, inst := range instances {
+			if proc := m.getProcess(inst); proc == nil || !isUp(proc) {
+				continue
+			}
+			if err := m.StopService(inst, opts); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 	proc := m.getProcess(name)
 	if proc == nil {
 		return fmt.Errorf("service %s not found", name)
 	}
 
+	if err := m.stopDependents(name, opts); err != nil {
+		return err
+	}
+
 	if err := proc.Stop(); err != nil {
 		return err
 	}
+	if !opts.Transient {
+		m.desired.set(name, model.DesiredStopped)
+	}
+	m.emitStopped(proc, "service stopped")
+
+	return nil
+}
 
+// emitStopped emits a service.stopped event recording whether the process
+// exited gracefully or had to be killed.
+func (m *Manager) emitStopped(proc *Process, message string) {
+	graceful := !proc.LastStopForced()
+	if graceful {
+		message += " (graceful)"
+	} else {
+		message += " (forced)"
+	}
 	m.emitEvent(model.Event{
 		Type:      model.EventServiceStopped,
-		Service:   name,
-		Message:   "service stopped",
+		Service:   proc.Config().Name,
+		Message:   message,
+		Data:      map[string]interface{}{"graceful": graceful},
 		Timestamp: time.Now(),
 	})
+}
+
+// stopDependents stops the active dependents of a service when cascading,
+// or refuses with a *model.DependentsError otherwise.
+func (m *Manager) stopDependents(name string, opts model.StopOptions) error {
+	dependents := m.activeDependents(name)
+	if len(dependents) == 0 {
+		return nil
+	}
+	if !opts.Cascade {
+		return &model.DependentsError{Service: name, Dependents: dependents}
+	}
 
+	for _, d := range dependents {
+		proc := m.getProcess(d)
+		if proc == nil || !isUp(proc) {
+			continue
+		}
+		if err := proc.Stop(); err != nil {
+			return fmt.Errorf("stop dependent %s: %w", d, err)
+		}
+		if !opts.Transient {
+			m.desired.set(d, model.DesiredStopped)
+		}
+		m.emitStopped(proc, "service stopped because dependency "+name+" is stopping")
+	}
 	return nil
 }
 
-// RestartService restarts a service.
+// RestartService restarts a service, or every instance of a multi-instance
+// service one after another.
 func (m *Manager) RestartService(name string) error {
+	if instances := m.instances(name); instances != nil {
+		for _, inst := range instances {
+			if err := m.RestartService(inst); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 	proc := m.getProcess(name)
 	if proc == nil {
 		return fmt.Errorf("service %s not found", name)
@@ -158,9 +359,12 @@ func (m *Manager) RestartService(name string) error {
 		}
 		// Brief pause
 		time.Sleep(500 * time.Millisecond)
+	} else if proc.RemainsCompleted() {
+		// Run a completed oneshot service again
+		_ = proc.Stop()
 	}
 
-	return m.StartService(name)
+	return m.StartService(name, model.StartOptions{})
 }
 
 // AddService adds a new service from config.
@@ -168,6 +372,9 @@ func (m *Manager) AddService(svc *config.ServiceConfig) error {
 	if err := svc.Validate(); err != nil {
 		return err
 	}
+	if err := m.validateDependencies(svc); err != nil {
+		return err
+	}
 
 	// Save to disk
 	if err := m.loader.SaveService(svc); err != nil {
@@ -187,12 +394,26 @@ func (m *Manager) AddService(svc *config.ServiceConfig) error {
 	return nil
 }
 
-// RemoveService removes a service (stops it first if running).
-func (m *Manager) RemoveService(name string) error {
-	proc := m.getProcess(name)
-	if proc != nil && proc.State() == model.StateRunning {
-		if err := proc.Stop(); err != nil {
-			return fmt.Errorf("stop before remove: %w", err)
+// RemoveService removes a service (stops it first if running). Active
+// dependents are handled as in StopService. The instances of a
+// multi-instance service can only be removed by scaling it down.
+func (m *Manager) RemoveService(name string, opts model.StopOptions) error {
+	if svc, _, ok := config.SplitInstanceName(name); ok && m.instances(svc) != nil {
+		return fmt.Errorf("%s is an instance of %s, scale %s down to remove it", name, svc, svc)
+	}
+
+	names := m.processNames(name)
+	for _, n := range names {
+		if m.getProcess(n) == nil {
+			continue
+		}
+		if err := m.stopDependents(n, opts); err != nil {
+			return err
+		}
+	}
+	for _, n := range names {
+		if err := m.removeProcess(n); err != nil {
+			return err
 		}
 	}
 
@@ -201,15 +422,6 @@ func (m *Manager) RemoveService(name string) error {
 		return err
 	}
 
-	// Remove from manager
-	m.mu.Lock()
-	if c, ok := m.collectors[name]; ok {
-		_ = c.Close()
-		delete(m.collectors, name)
-	}
-	delete(m.processes, name)
-	m.mu.Unlock()
-
 	m.emitEvent(model.Event{
 		Type:      model.EventServiceRemoved,
 		Service:   name,
@@ -220,20 +432,40 @@ func (m *Manager) RemoveService(name string) error {
 	return nil
 }
 
-// UpdateService updates a service's configuration.
-func (m *Manager) UpdateService(svc *config.ServiceConfig) error {
+// UpdateService updates a service's configuration. Changing the instance
+// count of a multi-instance service scales it as in ScaleService. Running
+// processes keep the command, arguments, environment and other launch
+// settings they were started with unless opts.Apply restarts them or sends
+// them their reload_signal.
+func (m *Manager) UpdateService(svc *config.ServiceConfig, opts model.UpdateOptions) error {
+	switch opts.Apply {
+	case "", model.ApplyNone, model.ApplyRestart, model.ApplyReloadSignal:
+	default:
+		return fmt.Errorf("invalid apply mode %q (expected one of none, restart, reload-signal)", opts.Apply)
+	}
+	old, ok := m.loader.GetService(svc.Name)
+	if ok && svc.AutoRestart != old.AutoRestart && svc.Restart == old.Restart {
+		// Only the legacy auto_restart flag was changed: derive the restart
+		// condition from it again rather than letting restart override it
+		svc.Restart = ""
+	}
 	if err := svc.Validate(); err != nil {
 		return err
 	}
+	if err := m.validateDependencies(svc); err != nil {
+		return err
+	}
 
-	proc := m.getProcess(svc.Name)
-	if proc != nil {
-		proc.UpdateConfig(svc)
+	if ok && old.IsMultiInstance() != svc.IsMultiInstance() {
+		return fmt.Errorf("service %s cannot be changed between a single and a multi-instance service, remove and add it again", svc.Name)
 	}
 
 	if err := m.loader.SaveService(svc); err != nil {
 		return err
 	}
+	if ok {
+		m.applyConfig(old, svc)
+	}
 
 	m.emitEvent(model.Event{
 		Type:      model.EventServiceUpdated,
@@ -242,6 +474,116 @@ func (m *Manager) UpdateService(svc *config.ServiceConfig) error {
 		Timestamp: time.Now(),
 	})
 
+	return m.applyRunning(svc.Name, opts.Apply)
+}
+
+// applyRunning restarts the running processes of a service whose launch
+// settings changed, or sends them their reload_signal, according to the
+// apply mode of an update.
+func (m *Manager) applyRunning(name, apply string) error {
+	if apply == "" || apply == model.ApplyNone {
+		return nil
+	}
+	for _, pname := range m.processNames(name) {
+		proc := m.getProcess(pname)
+		if proc == nil || !proc.RestartPending() {
+			continue
+		}
+		switch apply {
+		case model.ApplyRestart:
+			if err := m.RestartService(pname); err != nil {
+				return fmt.Errorf("service updated, but restart of %s failed: %w", pname, err)
+			}
+			m.emitEvent(model.Event{
+				Type:      model.EventServiceRestarted,
+				Service:   pname,
+				Message:   "service restarted (config changed)",
+				Data:      map[string]interface{}{"reason": "config"},
+				Timestamp: time.Now(),
+			})
+		case model.ApplyReloadSignal:
+			if err := proc.Reload(); err != nil {
+				return fmt.Errorf("service updated, but %w", err)
+			}
+			m.emitEvent(model.Event{
+				Type:      model.EventServiceReloaded,
+				Service:   pname,
+				Message:   "service reloaded (" + proc.Config().ReloadSignal + ")",
+				Timestamp: time.Now(),
+			})
+		}
+	}
+	return nil
+}
+
+// updateProcess updates the configuration of a registered process.
+func (m *Manager) updateProcess(cfg *config.ServiceConfig) {
+	proc := m.getProcess(cfg.Name)
+	if proc == nil {
+		return
+	}
+	// Changing auto_start overrides the last start or stop request
+	if proc.Config().AutoStart != cfg.AutoStart {
+		m.desired.remove(cfg.Name)
+	}
+	proc.UpdateConfig(cfg)
+}
+
+// applyConfig applies a changed service configuration to the registered
+// processes of the service. A multi-instance service is resized to its new
+// instance count; a service changed between a single and a multi-instance
+// service is registered again.
+func (m *Manager) applyConfig(old, svc *config.ServiceConfig) {
+	if old.IsMultiInstance() != svc.IsMultiInstance() {
+		m.unregisterService(old)
+		m.registerService(svc)
+		return
+	}
+	if !svc.IsMultiInstance() {
+		m.updateProcess(svc)
+		return
+	}
+	for i := 0; i < old.Instances && i < svc.Instances; i++ {
+		inst, err := svc.ForInstance(i)
+		if err != nil {
+			logger.Get().Errorf("failed to configure instance %d of %s: %v", i, svc.Name, err)
+			continue
+		}
+		m.updateProcess(inst)
+	}
+	m.resizeInstances(old, svc)
+}
+
+// unregisterService stops and removes the processes of a service.
+func (m *Manager) unregisterService(svc *config.ServiceConfig) {
+	for _, name := range processNamesOf(svc) {
+		if err := m.removeProcess(name); err != nil {
+			logger.Get().Errorf("failed to remove %s: %v", name, err)
+		}
+	}
+}
+
+// removeProcess stops a registered process if it is started and removes it
+// from the manager.
+func (m *Manager) removeProcess(name string) error {
+	proc := m.getProcess(name)
+	if proc == nil {
+		return nil
+	}
+	if isUp(proc) {
+		if err := proc.Stop(); err != nil {
+			return fmt.Errorf("stop before remove: %w", err)
+		}
+	}
+
+	m.mu.Lock()
+	if c, ok := m.collectors[name]; ok {
+		_ = c.Close()
+		delete(m.collectors, name)
+	}
+	delete(m.processes, name)
+	m.mu.Unlock()
+	m.desired.remove(name)
 	return nil
 }
 
@@ -252,9 +594,25 @@ func (m *Manager) GetServiceInfo(name string) (*model.ServiceInfo, error) {
 		return nil, fmt.Errorf("service %s not found", name)
 	}
 	info := proc.Info()
+	info.DesiredState = m.desiredState(proc)
 	return &info, nil
 }
 
+// GetServiceConfig returns a copy of the stored configuration of a service.
+// The configuration of a multi-instance service is shared by its instances
+// and is only available under the service name.
+func (m *Manager) GetServiceConfig(name string) (*config.ServiceConfig, error) {
+	svc, ok := m.loader.GetService(name)
+	if !ok {
+		if base, _, inst := config.SplitInstanceName(name); inst && m.instances(base) != nil {
+			return nil, fmt.Errorf("%s is an instance of %s, its configuration belongs to %s", name, base, base)
+		}
+		return nil, fmt.Errorf("service %s not found", name)
+	}
+	cfg := *svc
+	return &cfg, nil
+}
+
 // ListServices returns info for all services.
 func (m *Manager) ListServices() []model.ServiceInfo {
 	m.mu.RLock()
@@ -262,7 +620,9 @@ func (m *Manager) ListServices() []model.ServiceInfo {
 
 	var result []model.ServiceInfo
 	for _, proc := range m.processes {
-		result = append(result, proc.Info())
+		info := proc.Info()
+		info.DesiredState = m.desiredState(proc)
+		result = append(result, info)
 	}
 	return result
 }
@@ -280,28 +640,24 @@ func (m *Manager) GetServiceLogs(name string, n int) ([]model.LogEntry, error) {
 	return collector.GetLines(n), nil
 }
 
-// StopAll stops all running services gracefully.
+// StopAll stops all running services gracefully, in reverse dependency
+// order: a service is only stopped once everything depending on it is.
 func (m *Manager) StopAll() {
 	close(m.stopCh)
 
-	m.mu.RLock()
-	procs := make([]*Process, 0, len(m.processes))
-	for _, p := range m.processes {
-		procs = append(procs, p)
-	}
-	m.mu.RUnlock()
-
-	var wg sync.WaitGroup
-	for _, p := range procs {
-		if p.State() == model.StateRunning {
-			wg.Add(1)
-			go func(proc *Process) {
-				defer wg.Done()
-				_ = proc.Stop()
-			}(p)
+	for _, group := range m.stopLevels() {
+		var wg sync.WaitGroup
+		for _, p := range group {
+			if isActive(p.State()) {
+				wg.Add(1)
+				go func(proc *Process) {
+					defer wg.Done()
+					_ = proc.Stop()
+				}(p)
+			}
 		}
+		wg.Wait()
 	}
-	wg.Wait()
 
 	// Close collectors
 	m.mu.Lock()
@@ -312,7 +668,7 @@ func (m *Manager) StopAll() {
 }
 
 // Stats returns daemon-level statistics.
-func (m *Manager) Stats() (total, running, stopped, failed int) {
+func (m *Manager) Stats() (total, running, stopped, failed, backoff, crashLoop int) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -325,6 +681,10 @@ func (m *Manager) Stats() (total, running, stopped, failed int) {
 			stopped++
 		case model.StateFailed:
 			failed++
+		case model.StateBackoff:
+			backoff++
+		case model.StateCrashLoop:
+			crashLoop++
 		default:
 			stopped++
 		}
@@ -338,49 +698,15 @@ func (m *Manager) getProcess(name string) *Process {
 	return m.processes[name]
 }
 
-// resolveDependencies does a topological sort of services by depends_on.
+// resolveDependencies returns the services in boot order.
 func (m *Manager) resolveDependencies() []string {
-	services := m.loader.GetServices()
-
-	// Build adjacency
-	graph := make(map[string][]string)
-	inDegree := make(map[string]int)
-	for name := range services {
-		if _, ok := graph[name]; !ok {
-			graph[name] = nil
-		}
-		if _, ok := inDegree[name]; !ok {
-			inDegree[name] = 0
-		}
-	}
-	for name, svc := range services {
-		for _, dep := range svc.DependsOn {
-			graph[dep] = append(graph[dep], name)
-			inDegree[name]++
-		}
-	}
-
-	// Kahn's algorithm
-	var queue []string
-	for name, deg := range inDegree {
-		if deg == 0 {
-			queue = append(queue, name)
-		}
-	}
-
-	var order []string
-	for len(queue) > 0 {
-		node := queue[0]
-		queue = queue[1:]
-		order = append(order, node)
-
-		for _, neighbor := range graph[node] {
-			inDegree[neighbor]--
-			if inDegree[neighbor] == 0 {
-				queue = append(queue, neighbor)
-			}
-		}
-	}
+	return config.DependencyOrder(m.loader.GetServices())
+}
 
-	return order
+// validateDependencies checks that adding or updating svc keeps the
+// dependency graph free of missing services and cycles.
+func (m *Manager) validateDependencies(svc *config.ServiceConfig) error {
+	services := m.loader.GetServices()
+	services[svc.Name] = svc
+	return config.ValidateDependencies(services)
 }
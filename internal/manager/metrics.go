@@ -0,0 +1,81 @@
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BAIGUANGMEI/goser/internal/model"
+)
+
+// metricHistory is a bounded time series of resource usage samples. Each
+// resolution interval holds at most one point, the latest sample taken in it.
+type metricHistory struct {
+	points []model.MetricPoint
+}
+
+// add stores a sample and drops points older than the retention window.
+func (h *metricHistory) add(pt model.MetricPoint, resolution, retention time.Duration) {
+	if n := len(h.points); n > 0 && resolution > 0 &&
+		h.points[n-1].Timestamp.Truncate(resolution).Equal(pt.Timestamp.Truncate(resolution)) {
+		h.points[n-1] = pt
+	} else {
+		h.points = append(h.points, pt)
+	}
+
+	cutoff := pt.Timestamp.Add(-retention)
+	i := 0
+	for i < len(h.points) && h.points[i].Timestamp.Before(cutoff) {
+		i++
+	}
+	h.points = h.points[i:]
+}
+
+// query returns the points taken at or after since. If step is set, the
+// points are merged into buckets of that size, averaging the CPU usage and
+// keeping the peak of the other values.
+func (h *metricHistory) query(since time.Time, step time.Duration) []model.MetricPoint {
+	result := make([]model.MetricPoint, 0, len(h.points))
+	var count int
+	for _, pt := range h.points {
+		if pt.Timestamp.Before(since) {
+			continue
+		}
+		if step <= 0 {
+			result = append(result, pt)
+			continue
+		}
+
+		bucket := pt.Timestamp.Truncate(step)
+		if n := len(result); n > 0 && result[n-1].Timestamp.Equal(bucket) {
+			last := &result[n-1]
+			count++
+			last.CPU += (pt.CPU - last.CPU) / float64(count)
+			last.Memory = max(last.Memory, pt.Memory)
+			last.Threads = max(last.Threads, pt.Threads)
+			last.FDs = max(last.FDs, pt.FDs)
+			continue
+		}
+		pt.Timestamp = bucket
+		result = append(result, pt)
+		count = 1
+	}
+	return result
+}
+
+// Metrics returns the resource usage history of a service since the given
+// time, optionally downsampled to step.
+func (m *Manager) Metrics(name string, since time.Time, step time.Duration) (*model.ServiceMetrics, error) {
+	proc := m.getProcess(name)
+	if proc == nil {
+		return nil, fmt.Errorf("service %s not found", name)
+	}
+	resolution := step
+	if resolution <= 0 {
+		resolution = m.metricsResolution
+	}
+	return &model.ServiceMetrics{
+		Service: name,
+		Step:    resolution.String(),
+		Points:  proc.metrics(since, step),
+	}, nil
+}
@@ -1,41 +1,78 @@
 package manager
 
 import (
+	"errors"
+	"strconv"
 	"time"
 
+	"github.com/BAIGUANGMEI/goser/internal/config"
 	"github.com/BAIGUANGMEI/goser/internal/logger"
 	"github.com/BAIGUANGMEI/goser/internal/model"
 )
 
+// Reasons reported with service.restarted events.
+const (
+	restartReasonExited    = "exited"
+	restartReasonUnhealthy = "unhealthy"
+)
+
+// settingsPoll is how often the watchers of a running process look for a
+// health check or restart policy that was added to its configuration.
+const settingsPoll = 5 * time.Second
+
 // monitor watches a process and handles auto-restart logic.
 func (m *Manager) monitor(proc *Process) {
 	log := logger.Get()
-	cfg := proc.Config()
 
 	for {
-		// Wait for the process to exit
-		<-proc.DoneCh()
+		done := proc.DoneCh()
+		go m.healthCheck(proc, done)
+		go m.resetWhenStable(proc, done)
+		go m.runPlanned(proc, done)
 
-		state := proc.State()
-		if state == model.StateStopped {
+		// Wait for the process to exit. The restart settings are looked up
+		// afterwards, so that changes to them apply to the running process.
+		<-done
+		cfg := proc.Config()
+
+		if proc.StoppedIntentionally() {
 			// Intentionally stopped, don't restart
 			log.Infof("monitor: %s stopped intentionally, not restarting", cfg.Name)
 			return
 		}
 
-		if !cfg.AutoRestart {
-			log.Infof("monitor: %s exited, auto_restart is disabled", cfg.Name)
-			m.emitEvent(model.Event{
+		// A process terminated by the daemon (e.g. failing health checks)
+		// is restarted regardless of the restart condition.
+		reason := proc.TermReason()
+		code, signal := proc.ExitStatus()
+		if reason == "" && !shouldRestart(cfg, code, signal) {
+			exit := describeExit(code, signal)
+			why := "restart: " + cfg.Restart
+			if signal == "" && cfg.PreventsRestart(code) {
+				why = "listed in restart_prevent_exit_codes"
+			}
+			log.Infof("monitor: %s %s, not restarting (%s)", cfg.Name, exit, why)
+			event := model.Event{
 				Type:      model.EventServiceFailed,
 				Service:   cfg.Name,
-				Message:   "service exited and auto_restart is disabled",
+				Message:   "service " + exit + ", not restarting (" + why + ")",
+				Data:      map[string]interface{}{"exit_code": code, "signal": signal},
 				Timestamp: time.Now(),
-			})
+			}
+			switch proc.State() {
+			case model.StateStopped:
+				event.Type = model.EventServiceStopped
+			case model.StateCompleted:
+				event.Type = model.EventServiceCompleted
+				event.Message = "service completed"
+			}
+			m.emitEvent(event)
 			return
 		}
 
 		if proc.RestartCount() >= cfg.MaxRestarts {
 			log.Warnf("monitor: %s exceeded max_restarts (%d), giving up", cfg.Name, cfg.MaxRestarts)
+			proc.setCrashLoop("exceeded max restarts (service " + describeExit(code, signal) + ")")
 			m.emitEvent(model.Event{
 				Type:      model.EventServiceFailed,
 				Service:   cfg.Name,
@@ -45,20 +82,33 @@ func (m *Manager) monitor(proc *Process) {
 			return
 		}
 
+		if reason == "" {
+			reason = restartReasonExited
+		}
+
 		proc.IncrementRestartCount()
-		delay := cfg.RestartDelay
-		log.Infof("monitor: restarting %s in %s (attempt %d/%d)",
-			cfg.Name, delay, proc.RestartCount(), cfg.MaxRestarts)
+		attempt := proc.RestartCount()
+		delay := restartDelay(cfg, attempt)
+		cancel := proc.setBackoff(time.Now().Add(delay))
+		log.Infof("monitor: restarting %s (%s) in %s (attempt %d/%d)",
+			cfg.Name, reason, delay, attempt, cfg.MaxRestarts)
 
 		// Wait before restarting
 		select {
 		case <-time.After(delay):
+		case <-cancel:
+			log.Infof("monitor: pending restart of %s cancelled", cfg.Name)
+			return
 		case <-m.stopCh:
 			return
 		}
 
 		// Restart the process
-		if err := proc.Start(); err != nil {
+		if err := proc.restart(); err != nil {
+			if errors.Is(err, errRestartCancelled) {
+				log.Infof("monitor: pending restart of %s cancelled", cfg.Name)
+				return
+			}
 			log.Errorf("monitor: failed to restart %s: %v", cfg.Name, err)
 			m.emitEvent(model.Event{
 				Type:      model.EventServiceFailed,
@@ -70,10 +120,53 @@ func (m *Manager) monitor(proc *Process) {
 		}
 
 		m.emitEvent(model.Event{
-			Type:      model.EventServiceRestarted,
-			Service:   cfg.Name,
-			Message:   "service restarted",
+			Type:    model.EventServiceRestarted,
+			Service: cfg.Name,
+			Message: "service restarted (" + reason + ")",
+			Data: map[string]interface{}{
+				"reason":  reason,
+				"attempt": attempt,
+			},
 			Timestamp: time.Now(),
 		})
 	}
 }
+
+// shouldRestart applies the service's restart condition to how its process
+// exited. Exit codes listed in restart_prevent_exit_codes never restart.
+// An unknown exit status (code -1 without a signal, for adopted processes)
+// counts as a clean exit.
+func shouldRestart(cfg *config.ServiceConfig, code int, signal string) bool {
+	if signal == "" && cfg.PreventsRestart(code) {
+		return false
+	}
+	clean := signal == "" && (code < 0 || cfg.IsSuccessExit(code))
+
+	switch cfg.Restart {
+	case "always":
+		return true
+	case "on-failure":
+		return !clean
+	case "on-abnormal":
+		// Only deaths by signal count as abnormal; exit codes do not.
+		return signal != ""
+	}
+	return false
+}
+
+// describeExit formats how a process exited for logs and events.
+func describeExit(code int, signal string) string {
+	switch signal {
+	case "":
+	case "SIGXCPU":
+		return "killed by SIGXCPU (CPU time limit exceeded)"
+	case "SIGXFSZ":
+		return "killed by SIGXFSZ (file size limit exceeded)"
+	default:
+		return "killed by " + signal
+	}
+	if code < 0 {
+		return "exited"
+	}
+	return "exited with code " + strconv.Itoa(code)
+}
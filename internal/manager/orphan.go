@@ -0,0 +1,44 @@
+package manager
+
+import (
+	"time"
+
+	"github.com/BAIGUANGMEI/goser/internal/logger"
+	"github.com/BAIGUANGMEI/goser/internal/model"
+)
+
+// recoverOrphans handles the processes a previous daemon instance left
+// running, according to each service's orphan_policy: they are either
+// adopted and managed as if this daemon had started them, or killed.
+// Processes of services that no longer exist are killed.
+func (m *Manager) recoverOrphans() {
+	log := logger.Get()
+	for _, st := range loadRunStates(m.stateDir) {
+		if !st.alive() {
+			removeRunState(m.stateDir, st.Name)
+			removeSpool(m.stateDir, st.Name)
+			continue
+		}
+
+		proc := m.getProcess(st.Name)
+		if proc != nil && proc.Config().OrphanPolicy == "adopt" {
+			proc.adopt(st)
+			m.emitEvent(model.Event{
+				Type:      model.EventServiceAdopted,
+				Service:   st.Name,
+				Message:   "adopted running process from previous daemon",
+				Data:      map[string]interface{}{"pid": st.PID},
+				Timestamp: time.Now(),
+			})
+			go m.monitor(proc)
+			continue
+		}
+
+		log.Warnf("killing orphaned process tree of %s (PID %d)", st.Name, st.PID)
+		g := newProcGroup(st.PID)
+		g.sweep()
+		g.release()
+		removeRunState(m.stateDir, st.Name)
+		removeSpool(m.stateDir, st.Name)
+	}
+}
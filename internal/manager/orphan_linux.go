@@ -0,0 +1,43 @@
+package manager
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// setParentDeathSignal makes the kernel kill the command's main process
+// when the daemon dies. The signal is tied to the thread that started the
+// process; the Go runtime keeps threads alive unless they are locked by an
+// exiting goroutine, which the manager never does.
+func setParentDeathSignal(cmd *exec.Cmd) error {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Pdeathsig = syscall.SIGKILL
+	return nil
+}
+
+// parentDeathSignal returns the parent death signal set on the command.
+func parentDeathSignal(cmd *exec.Cmd) syscall.Signal {
+	if cmd.SysProcAttr == nil {
+		return 0
+	}
+	return cmd.SysProcAttr.Pdeathsig
+}
+
+// restoreParentDeathSignal sets the parent death signal of the calling
+// process again after it changed its credentials, which clears it. ppid is
+// the daemon's PID; it fails if the daemon has died in the meantime.
+func restoreParentDeathSignal(sig syscall.Signal, ppid int) error {
+	if err := unix.Prctl(unix.PR_SET_PDEATHSIG, uintptr(sig), 0, 0, 0); err != nil {
+		return err
+	}
+	if os.Getppid() != ppid {
+		return errors.New("daemon exited")
+	}
+	return nil
+}
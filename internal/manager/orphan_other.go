@@ -0,0 +1,24 @@
+//go:build !linux
+
+package manager
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+)
+
+// setParentDeathSignal is only implemented on Linux.
+func setParentDeathSignal(cmd *exec.Cmd) error {
+	return errors.New("parent death signals are not supported on this platform")
+}
+
+// parentDeathSignal is only implemented on Linux.
+func parentDeathSignal(cmd *exec.Cmd) syscall.Signal {
+	return 0
+}
+
+// restoreParentDeathSignal is only implemented on Linux.
+func restoreParentDeathSignal(sig syscall.Signal, ppid int) error {
+	return nil
+}
@@ -0,0 +1,208 @@
+package manager
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BAIGUANGMEI/goser/internal/logger"
+)
+
+// Output spooling. Where adoption is supported, a process writes its
+// stdout and stderr to spool files in the state directory instead of pipes
+// read by the daemon, so that it can keep writing if the daemon dies. The
+// daemon tails the spool files, and a new daemon that adopts the process
+// continues where the previous one stopped.
+
+const (
+	// spoolPollInterval is how often a spool file is checked for new
+	// output once everything written so far has been read.
+	spoolPollInterval = 200 * time.Millisecond
+	// spoolReleaseSize is how much output is read before the space it
+	// used in the spool file is released. A daemon that adopts the process
+	// after a crash collects at most this much output a second time.
+	spoolReleaseSize = 64 << 10
+)
+
+// processOutput holds the stdout and stderr of a run: the write ends passed
+// to the process and the read ends the daemon collects from.
+type processOutput struct {
+	stdout, stderr   io.ReadCloser
+	stdoutW, stderrW *os.File
+}
+
+func spoolPath(dir, name, stream string) string {
+	return filepath.Join(dir, name+"."+stream)
+}
+
+// openOutput creates the stdout and stderr of a new run of a service:
+// spool files in stateDir where supported, otherwise plain OS pipes, which
+// deliver output until every process holding them exits.
+func openOutput(stateDir, name string) (*processOutput, error) {
+	out := &processOutput{}
+	var err error
+	if spoolOutput && stateDir != "" {
+		if out.stdout, out.stdoutW, err = createSpool(spoolPath(stateDir, name, "stdout")); err == nil {
+			out.stderr, out.stderrW, err = createSpool(spoolPath(stateDir, name, "stderr"))
+		}
+	} else {
+		if out.stdout, out.stdoutW, err = os.Pipe(); err == nil {
+			out.stderr, out.stderrW, err = os.Pipe()
+		}
+	}
+	if err != nil {
+		out.closeWriters()
+		out.close()
+		return nil, err
+	}
+	return out, nil
+}
+
+// adoptOutput opens the spool files of a run started by a previous daemon.
+// Streams without a spool file are not collected.
+func adoptOutput(stateDir, name string) *processOutput {
+	out := &processOutput{}
+	if !spoolOutput || stateDir == "" {
+		return out
+	}
+	if r, err := openSpool(spoolPath(stateDir, name, "stdout")); err == nil {
+		out.stdout = r
+	}
+	if r, err := openSpool(spoolPath(stateDir, name, "stderr")); err == nil {
+		out.stderr = r
+	}
+	return out
+}
+
+// removeSpool deletes the spool files of a service.
+func removeSpool(stateDir, name string) {
+	_ = os.Remove(spoolPath(stateDir, name, "stdout"))
+	_ = os.Remove(spoolPath(stateDir, name, "stderr"))
+}
+
+// closeWriters closes the daemon's copies of the write ends once the
+// process has inherited them.
+func (o *processOutput) closeWriters() {
+	for _, w := range []*os.File{o.stdoutW, o.stderrW} {
+		if w != nil {
+			_ = w.Close()
+		}
+	}
+	o.stdoutW, o.stderrW = nil, nil
+}
+
+// close releases the read ends without collecting them.
+func (o *processOutput) close() {
+	for _, r := range []io.ReadCloser{o.stdout, o.stderr} {
+		if r != nil {
+			_ = r.Close()
+		}
+	}
+}
+
+// collect feeds the output to the collector in the background until the
+// run that closes done has ended.
+func (o *processOutput) collect(c *logger.Collector, done <-chan struct{}) {
+	for _, s := range []struct {
+		r      io.ReadCloser
+		stream string
+	}{{o.stdout, "stdout"}, {o.stderr, "stderr"}} {
+		if s.r == nil {
+			continue
+		}
+		if sr, ok := s.r.(*spoolReader); ok {
+			sr.done = done
+		}
+		go func(r io.ReadCloser, stream string) {
+			c.Collect(r, stream)
+			_ = r.Close()
+		}(s.r, s.stream)
+	}
+}
+
+// spoolReader reads a spool file as the process appends to it. It returns
+// io.EOF once done is closed and the remaining output has been read, and
+// releases the disk space of the output it has read as it goes.
+type spoolReader struct {
+	f        *os.File
+	done     <-chan struct{}
+	ended    bool
+	skipNUL  bool // skip the zeroed remainder of a released block
+	offset   int64
+	released int64
+}
+
+// createSpool creates a new spool file. A spool file left by the previous
+// run is unlinked rather than truncated, as it may still be being read.
+func createSpool(path string) (*spoolReader, *os.File, error) {
+	_ = os.Remove(path)
+	w, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		_ = w.Close()
+		_ = os.Remove(path)
+		return nil, nil, err
+	}
+	return &spoolReader{f: f}, w, nil
+}
+
+// openSpool opens an existing spool file at the first output that has not
+// been released, i.e. read by the previous daemon.
+func openSpool(path string) (*spoolReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	offset := seekData(f)
+	return &spoolReader{f: f, skipNUL: true, offset: offset, released: offset}, nil
+}
+
+func (r *spoolReader) Read(b []byte) (int, error) {
+	for {
+		n, err := r.f.Read(b)
+		if n > 0 {
+			r.offset += int64(n)
+			if r.offset-r.released >= spoolReleaseSize {
+				releaseSpool(r.f, r.offset)
+				r.released = r.offset
+			}
+			if r.skipNUL {
+				rest := bytes.TrimLeft(b[:n], "\x00")
+				if len(rest) == 0 {
+					continue
+				}
+				r.skipNUL = false
+				n = copy(b, rest)
+			}
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if r.ended {
+			return 0, io.EOF
+		}
+		select {
+		case <-r.done:
+			// Read whatever was written before the run ended
+			r.ended = true
+		case <-time.After(spoolPollInterval):
+		}
+	}
+}
+
+// Close closes and removes the spool file, unless it has already been
+// replaced by the spool file of a new run.
+func (r *spoolReader) Close() error {
+	if fi, err := r.f.Stat(); err == nil {
+		if cur, err := os.Stat(r.f.Name()); err == nil && os.SameFile(fi, cur) {
+			_ = os.Remove(r.f.Name())
+		}
+	}
+	return r.f.Close()
+}
@@ -0,0 +1,36 @@
+//go:build linux
+
+package manager
+
+import (
+	"io"
+	"os"
+	"syscall"
+)
+
+// spoolOutput reports whether process output goes through spool files.
+// Adoption is only supported on Linux.
+const spoolOutput = true
+
+const (
+	fallocKeepSize  = 0x01
+	fallocPunchHole = 0x02
+	seekDataWhence  = 3 // SEEK_DATA
+)
+
+// releaseSpool frees the disk space of the first size bytes of a spool
+// file. The file keeps its size; the released range reads as zeros.
+func releaseSpool(f *os.File, size int64) {
+	_ = syscall.Fallocate(int(f.Fd()), fallocKeepSize|fallocPunchHole, 0, size)
+}
+
+// seekData moves to the first byte of a spool file that has not been
+// released and returns its offset.
+func seekData(f *os.File) int64 {
+	offset, err := f.Seek(0, seekDataWhence)
+	if err != nil {
+		// No data left, or SEEK_DATA is not supported
+		offset, _ = f.Seek(0, io.SeekEnd)
+	}
+	return offset
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+
+package manager
+
+import "os"
+
+// spoolOutput reports whether process output goes through spool files.
+// Adoption is only supported on Linux.
+const spoolOutput = false
+
+// releaseSpool is only implemented on Linux.
+func releaseSpool(f *os.File, size int64) {}
+
+// seekData is only implemented on Linux.
+func seekData(f *os.File) int64 {
+	return 0
+}
@@ -0,0 +1,109 @@
+package manager
+
+import (
+	"time"
+
+	"github.com/BAIGUANGMEI/goser/internal/config"
+	"github.com/BAIGUANGMEI/goser/internal/logger"
+	"github.com/BAIGUANGMEI/goser/internal/model"
+)
+
+// restartReasonScheduled is reported when a service is restarted by
+// max_runtime, restart_every or restart_at.
+const restartReasonScheduled = "scheduled"
+
+// plannedAction is a stop or restart of a running process planned by
+// max_runtime, restart_every or restart_at.
+type plannedAction struct {
+	at     time.Time
+	action string // stop | restart
+	option string // the option that planned it
+}
+
+// nextPlanned returns the earliest planned action for a process that
+// started at startedAt.
+func nextPlanned(cfg *config.ServiceConfig, startedAt, now time.Time) (plannedAction, bool) {
+	var next plannedAction
+	found := false
+	consider := func(at time.Time, action, option string) {
+		if at.IsZero() || (found && !at.Before(next.at)) {
+			return
+		}
+		next = plannedAction{at: at, action: action, option: option}
+		found = true
+	}
+
+	if cfg.MaxRuntime > 0 {
+		consider(startedAt.Add(cfg.MaxRuntime), cfg.MaxRuntimeAction, "max_runtime")
+	}
+	if cfg.RestartEvery > 0 {
+		consider(startedAt.Add(cfg.RestartEvery), "restart", "restart_every")
+	}
+	if s := cfg.RestartAtSchedule(); s != nil {
+		consider(s.Next(now), "restart", "restart_at")
+	}
+	return next, found
+}
+
+// runPlanned waits for the next planned stop or restart of a running
+// process and carries it out through the graceful stop path.
+func (m *Manager) runPlanned(proc *Process, done <-chan struct{}) {
+	next, ok := nextPlanned(proc.Config(), proc.StartedAt(), time.Now())
+	if !ok || !proc.setPlanned(done, &next) {
+		return
+	}
+
+	timer := time.NewTimer(time.Until(next.at))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-done:
+		return
+	case <-m.stopCh:
+		return
+	}
+
+	// The run may have ended in the meantime.
+	if proc.DoneCh() != done || proc.State() != model.StateRunning {
+		return
+	}
+
+	log := logger.Get()
+	name := proc.Config().Name
+	switch next.action {
+	case "stop":
+		log.Infof("monitor: stopping %s (%s reached)", name, next.option)
+		if err := proc.Stop(); err != nil {
+			log.Errorf("monitor: failed to stop %s: %v", name, err)
+			return
+		}
+		m.emitStopped(proc, "service stopped ("+next.option+" reached)")
+	case "restart":
+		log.Infof("monitor: restarting %s (%s)", name, next.option)
+		if err := proc.Stop(); err != nil {
+			log.Errorf("monitor: failed to stop %s for restart: %v", name, err)
+			return
+		}
+		if err := m.startProcess(proc); err != nil {
+			log.Errorf("monitor: failed to restart %s: %v", name, err)
+			m.emitEvent(model.Event{
+				Type:      model.EventServiceFailed,
+				Service:   name,
+				Message:   "restart failed: " + err.Error(),
+				Timestamp: time.Now(),
+			})
+			return
+		}
+		m.emitEvent(model.Event{
+			Type:    model.EventServiceRestarted,
+			Service: name,
+			Message: "service restarted (" + restartReasonScheduled + ")",
+			Data: map[string]interface{}{
+				"reason": restartReasonScheduled,
+				"option": next.option,
+			},
+			Timestamp: time.Now(),
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package manager
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,92 +20,183 @@ import (
 type Process struct {
 	mu           sync.RWMutex
 	config       *config.ServiceConfig
+	revision     int                   // incremented by each configuration update
+	runConfig    *config.ServiceConfig // configuration the current run uses
+	runRevision  int
 	cmd          *exec.Cmd
+	group        *procGroup
 	state        model.ServiceState
 	pid          int
+	startTime    uint64 // process start time fingerprint, see runState
+	adopted      bool
 	exitCode     *int
+	exitSignal   string
+	stopped      bool
 	startedAt    *time.Time
 	stoppedAt    *time.Time
 	restartCount int
+	nextRestart  *time.Time
+	nextRun      *time.Time
+	planned      *plannedAction
+	runQueued    bool
+	runs         []model.JobRun
+	cancelCh     chan struct{}
 	lastError    string
+	health       healthState
+	usage        resourceUsage
+	history      metricHistory
+	watchdog     watchdogState
+	termReason   string
+	forcedStop   bool
 	collector    *logger.Collector
+	stateDir     string
 	stopCh       chan struct{}
 	doneCh       chan struct{}
 }
 
-// NewProcess creates a new Process for the given service config.
-func NewProcess(cfg *config.ServiceConfig, collector *logger.Collector) *Process {
+// NewProcess creates a new Process for the given service config. If stateDir
+// is not empty, the runtime state of the process is persisted there while it
+// runs.
+func NewProcess(cfg *config.ServiceConfig, collector *logger.Collector, stateDir string) *Process {
 	return &Process{
 		config:    cfg,
+		revision:  1,
 		state:     model.StateStopped,
+		health:    newHealthState(),
 		collector: collector,
+		stateDir:  stateDir,
 	}
 }
 
-// Start launches the child process.
+// errAdoptedExit is reported when an adopted process exits; its exit status
+// is not available because it is not a child of the daemon.
+var errAdoptedExit = errors.New("adopted process exited, exit status unknown")
+
+// adoptPollInterval is how often an adopted process is checked for exit.
+const adoptPollInterval = time.Second
+
+// errRestartCancelled is returned by restart when the pending automatic
+// restart was cancelled by a manual start or stop.
+var errRestartCancelled = errors.New("pending restart cancelled")
+
+// Start launches the child process, cancelling any pending automatic restart
+// and resetting the restart counter.
 func (p *Process) Start() error {
+	return p.start(false)
+}
+
+// restart launches the child process for the monitor's pending automatic
+// restart. It fails with errRestartCancelled if the service has left the
+// backoff state in the meantime.
+func (p *Process) restart() error {
+	return p.start(true)
+}
+
+func (p *Process) start(auto bool) error {
 	p.mu.Lock()
-	if p.state == model.StateRunning || p.state == model.StateStarting {
+	if auto && p.state != model.StateBackoff {
+		p.mu.Unlock()
+		return errRestartCancelled
+	}
+	if p.state == model.StateRunning || p.state == model.StateStarting || p.remainsCompleted() {
 		p.mu.Unlock()
 		return fmt.Errorf("service %s is already %s", p.config.Name, p.state)
 	}
+	p.cancelRestart()
 	p.state = model.StateStarting
+	// The run is launched from this configuration only, even if it is
+	// updated meanwhile.
+	cfg := p.config
+	p.runConfig, p.runRevision = cfg, p.revision
 	p.mu.Unlock()
 
 	log := logger.Get()
-	log.Infof("starting service: %s", p.config.Name)
+	log.Infof("starting service: %s", cfg.Name)
 
-	cmd := exec.Command(p.config.Command, p.config.Args...)
+	cmd := exec.Command(cfg.Command, cfg.Args...)
 
-	// Set working directory
-	if p.config.WorkingDir != "" {
-		cmd.Dir = p.config.WorkingDir
+	// Run in a separate process group so the whole tree can be stopped
+	setProcAttr(cmd)
+	if cfg.OrphanPolicy == "kill-on-daemon-death" {
+		if err := setParentDeathSignal(cmd); err != nil {
+			log.Warnf("orphan_policy kill-on-daemon-death for %s: %v", cfg.Name, err)
+		}
 	}
 
-	// Set environment
-	if len(p.config.Env) > 0 {
-		cmd.Env = os.Environ()
-		for k, v := range p.config.Env {
-			cmd.Env = append(cmd.Env, k+"="+v)
+	// Set working directory, environment, and user and group
+	cred, err := prepareCommand(cmd, cfg)
+	if err != nil {
+		p.setFailed(err.Error())
+		return fmt.Errorf("start %s: %w", cfg.Name, err)
+	}
+	if cred != nil && os.Geteuid() == 0 {
+		if err := p.collector.Chown(int(cred.UID), int(cred.GID)); err != nil {
+			log.Warnf("failed to change owner of log file for %s: %v", cfg.Name, err)
 		}
 	}
 
-	// Pipe stdout and stderr to log collector
-	stdout, err := cmd.StdoutPipe()
+	// Collect stdout and stderr. Output goes through spool files where
+	// processes can be adopted, so that it survives a daemon crash, and
+	// otherwise through OS pipes; either way it is collected until the run
+	// has ended, independent of when cmd.Wait returns.
+	out, err := openOutput(p.stateDir, cfg.Name)
 	if err != nil {
-		p.setFailed(fmt.Sprintf("stdout pipe: %v", err))
+		p.setFailed(fmt.Sprintf("output: %v", err))
 		return err
 	}
-	stderr, err := cmd.StderrPipe()
+	cmd.Stdout = out.stdoutW
+	cmd.Stderr = out.stderrW
+
+	// Resource limits and the umask are applied by an exec shim before the
+	// command runs
+	if !rlimitsSupported && cfg.Limits != nil && len(cfg.Limits.Rlimits()) > 0 {
+		log.Warnf("resource limits are not supported on this platform, ignoring them for %s", cfg.Name)
+	}
+	shim, err := setPreExec(cmd, cfg)
 	if err != nil {
-		p.setFailed(fmt.Sprintf("stderr pipe: %v", err))
-		return err
+		out.closeWriters()
+		out.close()
+		p.setFailed(err.Error())
+		return fmt.Errorf("start %s: %w", cfg.Name, err)
 	}
 
 	// Start the process
-	if err := cmd.Start(); err != nil {
+	err = cmd.Start()
+	out.closeWriters()
+	if err != nil {
+		shim.close()
+		out.close()
 		p.setFailed(fmt.Sprintf("start: %v", err))
-		return fmt.Errorf("start %s: %w", p.config.Name, err)
+		return fmt.Errorf("start %s: %w", cfg.Name, err)
+	}
+	group := newProcGroup(cmd.Process.Pid)
+	err = shim.started()
+	if err == nil {
+		err = applyScheduling(cmd.Process.Pid, cfg)
 	}
+	if err != nil {
+		group.sweep()
+		group.release()
+		_ = cmd.Wait()
+		out.close()
+		p.setFailed(err.Error())
+		return fmt.Errorf("start %s: %w", cfg.Name, err)
+	}
+	startTime, _ := procStartTime(cmd.Process.Pid)
 
-	now := time.Now()
 	p.mu.Lock()
 	p.cmd = cmd
-	p.pid = cmd.Process.Pid
-	p.startedAt = &now
-	p.stoppedAt = nil
-	p.exitCode = nil
-	p.lastError = ""
-	p.state = model.StateRunning
-	p.stopCh = make(chan struct{})
-	p.doneCh = make(chan struct{})
+	if !auto {
+		p.restartCount = 0
+	}
+	p.setRunning(cmd.Process.Pid, startTime, group, time.Now())
+	done := p.doneCh
 	p.mu.Unlock()
 
-	log.Infof("service %s started with PID %d", p.config.Name, p.pid)
+	log.Infof("service %s started with PID %d", cfg.Name, cmd.Process.Pid)
 
 	// Collect logs in background
-	go p.collector.Collect(stdout, "stdout")
-	go p.collector.Collect(stderr, "stderr")
+	out.collect(p.collector, done)
 
 	// Wait for process to exit in background
 	go p.wait()
@@ -109,95 +204,372 @@ func (p *Process) Start() error {
 	return nil
 }
 
+// adopt attaches to a process left running by a previous daemon instance.
+// The process is watched until it exits, and its output is collected from
+// the spool files the previous daemon was reading.
+func (p *Process) adopt(st runState) {
+	p.mu.Lock()
+	p.cmd = nil
+	p.restartCount = st.RestartCount
+	p.setRunning(st.PID, st.StartTime, newProcGroup(st.PID), st.StartedAt)
+	p.adopted = true
+	p.runConfig, p.runRevision = p.config, p.revision
+	done := p.doneCh
+	p.mu.Unlock()
+
+	adoptOutput(p.stateDir, p.config.Name).collect(p.collector, done)
+	logger.Get().Infof("adopted service %s with PID %d", p.config.Name, st.PID)
+	go p.watchAdopted(st)
+}
+
+// setRunning resets the runtime state for a newly running process and
+// persists it. The caller must hold p.mu.
+func (p *Process) setRunning(pid int, startTime uint64, group *procGroup, startedAt time.Time) {
+	p.group = group
+	p.pid = pid
+	p.startTime = startTime
+	p.adopted = false
+	p.startedAt = &startedAt
+	p.stoppedAt = nil
+	p.exitCode = nil
+	p.exitSignal = ""
+	p.stopped = false
+	p.lastError = ""
+	p.health = newHealthState()
+	p.usage = resourceUsage{}
+	p.watchdog = watchdogState{}
+	p.termReason = ""
+	p.planned = nil
+	p.state = model.StateRunning
+	p.stopCh = make(chan struct{})
+	p.doneCh = make(chan struct{})
+	p.saveRunState()
+}
+
+// saveRunState persists the runtime state of the running process. The
+// caller must hold p.mu.
+func (p *Process) saveRunState() {
+	if p.stateDir == "" || p.state != model.StateRunning {
+		return
+	}
+	st := runState{
+		Name:         p.config.Name,
+		PID:          p.pid,
+		StartTime:    p.startTime,
+		StartedAt:    *p.startedAt,
+		RestartCount: p.restartCount,
+	}
+	if err := saveRunState(p.stateDir, st); err != nil {
+		logger.Get().Warnf("failed to save state of %s: %v", p.config.Name, err)
+	}
+}
+
 // wait monitors the process until it exits.
 func (p *Process) wait() {
+	err := p.cmd.Wait()
+	p.exited(err, p.cmd.ProcessState)
+}
+
+// watchAdopted polls an adopted process until it exits.
+func (p *Process) watchAdopted(st runState) {
+	ticker := time.NewTicker(adoptPollInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if !st.alive() {
+			break
+		}
+	}
+	p.exited(errAdoptedExit, nil)
+}
+
+// exited records the exit of the process. ps is nil if the exit status
+// is unknown.
+func (p *Process) exited(err error, ps *os.ProcessState) {
 	defer close(p.doneCh)
 
-	err := p.cmd.Wait()
+	// Kill whatever is left of the process tree before reporting the
+	// service as stopped.
+	if p.Config().KillMode != "process" {
+		p.group.sweep()
+	}
+	p.group.release()
 	now := time.Now()
 
 	p.mu.Lock()
 	p.stoppedAt = &now
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			code := exitErr.ExitCode()
-			p.exitCode = &code
-		}
-		// Only set to failed if we didn't intentionally stop it
-		if p.state != model.StateStopping {
-			p.lastError = err.Error()
-			p.state = model.StateFailed
-		} else {
-			p.state = model.StateStopped
-		}
-	} else {
-		code := 0
+	if ps != nil {
+		code := ps.ExitCode()
 		p.exitCode = &code
-		if p.state != model.StateStopping {
-			p.state = model.StateStopped
+		p.exitSignal = exitSignal(ps)
+	}
+
+	switch {
+	case p.state == model.StateStopping:
+		// Intentionally stopped
+		p.stopped = true
+		p.state = model.StateStopped
+	case p.termReason != "":
+		// Terminated by the daemon, even if the process exited cleanly
+		p.lastError = "terminated (" + p.termReason + ")"
+		if err != nil {
+			p.lastError += ": " + err.Error()
+		}
+		p.state = model.StateFailed
+	case errors.Is(err, errAdoptedExit):
+		// The exit status of an adopted process is unknown, which is
+		// not reported as a failure
+		p.lastError = "exit status unknown"
+		if p.config.IsOneshot() {
+			p.state = model.StateCompleted
 		} else {
 			p.state = model.StateStopped
 		}
+	case err != nil && (p.exitCode == nil || !p.config.IsSuccessExit(*p.exitCode)):
+		p.lastError = err.Error()
+		p.state = model.StateFailed
+	case p.config.IsOneshot():
+		// A oneshot service ran to completion
+		p.state = model.StateCompleted
+	default:
+		// Exited cleanly with 0 or one of success_exit_codes
+		p.state = model.StateStopped
+	}
+	if p.config.Schedule != "" {
+		p.recordRun(now)
 	}
 	p.pid = 0
+	p.adopted = false
+	p.planned = nil
+	name, state, exitCode, signal := p.config.Name, p.state, p.exitCode, p.exitSignal
 	p.mu.Unlock()
+	if p.stateDir != "" {
+		removeRunState(p.stateDir, name)
+	}
 
 	log := logger.Get()
-	log.Infof("service %s exited (exit_code=%v, state=%s)", p.config.Name, p.exitCode, p.state)
+	if signal != "" {
+		log.Infof("service %s exited (signal=%s, state=%s)", name, signal, state)
+	} else if exitCode != nil {
+		log.Infof("service %s exited (exit_code=%d, state=%s)", name, *exitCode, state)
+	} else {
+		log.Infof("service %s exited (state=%s)", name, state)
+	}
 }
 
-// Stop gracefully stops the child process.
+// Stop gracefully stops the child process: the stop_command is run or the
+// stop_signal is delivered, and the process is killed if it has not exited
+// within stop_timeout. A service that is waiting for its dependencies or
+// for an automatic restart, or a completed oneshot service with
+// remain_after_exit, is simply moved back to the stopped state.
 func (p *Process) Stop() error {
 	p.mu.Lock()
+	if p.state == model.StateWaiting || p.state == model.StateBackoff || p.remainsCompleted() {
+		p.cancelRestart()
+		p.state = model.StateStopped
+		p.mu.Unlock()
+		return nil
+	}
 	if p.state != model.StateRunning {
 		p.mu.Unlock()
 		return fmt.Errorf("service %s is not running (state=%s)", p.config.Name, p.state)
 	}
 	p.state = model.StateStopping
-	cmd := p.cmd
 	p.mu.Unlock()
 
+	p.shutdown()
+	return nil
+}
+
+// shutdown asks the running process to exit and escalates to a kill after
+// stop_timeout, which covers both the stop_command and the wait for the
+// process to exit. It blocks until the process has exited.
+func (p *Process) shutdown() {
+	p.mu.RLock()
+	cfg := p.config
+	pid := p.pid
+	group := p.group
+	done := p.doneCh
+	p.mu.RUnlock()
+
 	log := logger.Get()
-	log.Infof("stopping service: %s (PID %d)", p.config.Name, cmd.Process.Pid)
+	log.Infof("stopping service: %s (PID %d)", cfg.Name, pid)
 
-	// Send kill signal (Windows doesn't support SIGTERM well, use Kill)
-	if err := cmd.Process.Kill(); err != nil {
-		log.Warnf("failed to kill %s: %v", p.config.Name, err)
-		return err
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.StopTimeout)
+	defer cancel()
+
+	graceful := true
+	if err := p.requestStop(ctx, cfg, pid, group); err != nil {
+		log.Warnf("graceful stop of %s failed, killing: %v", cfg.Name, err)
+		graceful = false
+	} else {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			log.Warnf("service %s did not stop within %s, force killing", cfg.Name, cfg.StopTimeout)
+			graceful = false
+		}
 	}
 
-	// Wait for the process to finish with a timeout
-	select {
-	case <-p.doneCh:
-		log.Infof("service %s stopped", p.config.Name)
-	case <-time.After(p.config.StopTimeout):
-		log.Warnf("service %s stop timeout, force killing", p.config.Name)
-		_ = cmd.Process.Kill()
+	if !graceful {
+		if err := group.kill(cfg.KillMode); err != nil {
+			log.Warnf("failed to kill %s: %v", cfg.Name, err)
+		}
+		<-done
 	}
 
+	p.mu.Lock()
+	p.forcedStop = !graceful
+	p.mu.Unlock()
+	log.Infof("service %s stopped (graceful=%v)", cfg.Name, graceful)
+}
+
+// requestStop runs the service's stop_command until ctx is done, or
+// delivers its stop_signal if no command is configured or the command fails.
+func (p *Process) requestStop(ctx context.Context, cfg *config.ServiceConfig, pid int, group *procGroup) error {
+	if cfg.StopCommand != "" {
+		cmd := shellCommand(ctx, cfg.StopCommand)
+		_, err := prepareCommand(cmd, cfg, "MAINPID="+strconv.Itoa(pid))
+		var out []byte
+		if err == nil {
+			out, err = cmd.CombinedOutput()
+		}
+		if err == nil {
+			return nil
+		}
+		logger.Get().Warnf("stop_command for %s failed: %v: %s", cfg.Name, err, strings.TrimSpace(string(out)))
+	}
+	return group.signal(cfg.StopSignal, cfg.KillMode)
+}
+
+// remainsCompleted reports whether the service is a oneshot service with
+// remain_after_exit that has completed, and so still counts as started.
+// The caller must hold p.mu.
+func (p *Process) remainsCompleted() bool {
+	return p.state == model.StateCompleted && p.config.RemainAfterExit
+}
+
+// RemainsCompleted reports whether the service is a completed oneshot
+// service with remain_after_exit.
+func (p *Process) RemainsCompleted() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.remainsCompleted()
+}
+
+// LastStopForced reports whether the last stop had to kill the process
+// because it did not exit gracefully.
+func (p *Process) LastStopForced() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.forcedStop
+}
+
+// SetWaiting marks the service as waiting for its dependencies.
+func (p *Process) SetWaiting() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch p.state {
+	case model.StateRunning, model.StateStarting, model.StateStopping, model.StateWaiting:
+		return fmt.Errorf("service %s is already %s", p.config.Name, p.state)
+	}
+	p.cancelRestart()
+	p.state = model.StateWaiting
+	p.lastError = ""
+	return nil
+}
+
+// Terminate stops the running process without marking the stop as intentional,
+// so the monitor treats the exit as a failure and applies the restart policy.
+// The reason is reported with the restart.
+func (p *Process) Terminate(reason string) error {
+	p.mu.Lock()
+	if p.state != model.StateRunning {
+		p.mu.Unlock()
+		return fmt.Errorf("service %s is not running (state=%s)", p.config.Name, p.state)
+	}
+	p.termReason = reason
+	p.mu.Unlock()
+
+	p.shutdown()
 	return nil
 }
 
+// ExitStatus returns the exit code and terminating signal of the last run.
+// The code is -1 if the process was killed by a signal or the status is unknown.
+func (p *Process) ExitStatus() (code int, signal string) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.exitCode == nil {
+		return -1, p.exitSignal
+	}
+	return *p.exitCode, p.exitSignal
+}
+
+// StoppedIntentionally reports whether the last run ended because of a
+// stop request rather than the process exiting on its own.
+func (p *Process) StoppedIntentionally() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.stopped
+}
+
+// TermReason returns the reason passed to Terminate for the last run,
+// or an empty string if the process exited on its own.
+func (p *Process) TermReason() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.termReason
+}
+
 // Info returns the current runtime info for this process.
 func (p *Process) Info() model.ServiceInfo {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
 	info := model.ServiceInfo{
-		Name:         p.config.Name,
-		State:        p.state,
-		PID:          p.pid,
-		Command:      p.config.Command,
-		Args:         p.config.Args,
-		WorkingDir:   p.config.WorkingDir,
-		Env:          p.config.Env,
-		AutoStart:    p.config.AutoStart,
-		AutoRestart:  p.config.AutoRestart,
-		RestartCount: p.restartCount,
-		StartedAt:    p.startedAt,
-		StoppedAt:    p.stoppedAt,
-		ExitCode:     p.exitCode,
-		Error:        p.lastError,
+		Name:          p.config.Name,
+		State:         p.state,
+		PID:           p.pid,
+		Type:          p.config.Type,
+		Command:       p.config.Command,
+		Args:          p.config.Args,
+		WorkingDir:    p.config.WorkingDir,
+		Env:           p.config.Env,
+		User:          p.config.User,
+		Group:         p.config.Group,
+		AutoStart:     p.config.AutoStart,
+		AutoRestart:   p.config.AutoRestart,
+		Restart:       p.config.Restart,
+		RestartCount:  p.restartCount,
+		MaxRestarts:   p.config.MaxRestarts,
+		NextRestartAt: p.nextRestart,
+		StartedAt:     p.startedAt,
+		StoppedAt:     p.stoppedAt,
+		ExitCode:      p.exitCode,
+		ExitSignal:    p.exitSignal,
+		Error:         p.lastError,
+		Adopted:       p.adopted,
+		Schedule:      p.config.Schedule,
+
+		ConfigRevision: p.revision,
+	}
+	if p.nextRun != nil && !p.nextRun.IsZero() {
+		info.NextRunAt = p.nextRun
+	}
+	if p.state == model.StateRunning || p.state == model.StateStarting {
+		info.RunRevision = p.runRevision
+		info.RestartPending = p.restartPending()
+	}
+	if p.planned != nil {
+		info.NextPlannedAt = &p.planned.at
+		info.PlannedAction = p.planned.action + " (" + p.planned.option + ")"
+	}
+	if svc, _, ok := config.SplitInstanceName(p.config.Name); ok {
+		info.InstanceOf = svc
+	}
+	if len(p.runs) > 0 {
+		info.Runs = make([]model.JobRun, len(p.runs))
+		copy(info.Runs, p.runs)
 	}
 
 	if p.state == model.StateRunning && p.startedAt != nil {
@@ -205,6 +577,21 @@ func (p *Process) Info() model.ServiceInfo {
 		info.Uptime = formatDuration(uptime)
 	}
 
+	if p.state == model.StateRunning {
+		info.CPU = p.usage.cpu
+		info.Memory = p.usage.memory
+		if p.config.Limits != nil {
+			info.Limits = readLimits(p.pid, p.config.Limits)
+		}
+		if c := p.config; c.Nice != nil || c.IOPrio != nil || c.OOMScoreAdj != nil || c.Umask != "" {
+			info.Scheduling = readScheduling(p.pid)
+		}
+	}
+
+	if p.config.HealthCheck != nil {
+		info.Health = p.health.info()
+	}
+
 	return info
 }
 
@@ -222,11 +609,44 @@ func (p *Process) Config() *config.ServiceConfig {
 	return p.config
 }
 
-// UpdateConfig updates the service configuration.
+// UpdateConfig updates the service configuration. A running process keeps
+// the settings it was started with until it is restarted, see
+// RestartPending.
 func (p *Process) UpdateConfig(cfg *config.ServiceConfig) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	p.config = cfg
+	p.revision++
+	p.nextRun = nil
+}
+
+// restartPending reports whether the running process was started with
+// settings that have changed since. The caller must hold p.mu.
+func (p *Process) restartPending() bool {
+	return p.runConfig != nil && p.runConfig.NeedsRestart(p.config)
+}
+
+// RestartPending reports whether the process is running with settings that
+// have changed since it was started.
+func (p *Process) RestartPending() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.state == model.StateRunning && p.restartPending()
+}
+
+// Reload sends the reload_signal to the main process, which is then
+// considered to run with the current configuration.
+func (p *Process) Reload() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.state != model.StateRunning || p.group == nil {
+		return fmt.Errorf("service %s is not running", p.config.Name)
+	}
+	if err := p.group.signal(p.config.ReloadSignal, "process"); err != nil {
+		return fmt.Errorf("reload %s: %w", p.config.Name, err)
+	}
+	p.runConfig, p.runRevision = p.config, p.revision
+	return nil
 }
 
 // DoneCh returns a channel that is closed when the process exits.
@@ -241,6 +661,94 @@ func (p *Process) DoneCh() <-chan struct{} {
 	return p.doneCh
 }
 
+// recordHealth stores a health probe result and returns the health status
+// before and after it was applied.
+func (p *Process) recordHealth(result model.HealthResult, hc *config.HealthCheckConfig) (prev, cur model.HealthStatus) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	inStartPeriod := p.startedAt != nil && result.Timestamp.Sub(*p.startedAt) < hc.StartPeriod
+	prev = p.health.status
+	cur = p.health.record(result, hc, inStartPeriod)
+	return prev, cur
+}
+
+// resetHealth forgets the health check results of the current run, so that
+// a health check added later starts afresh.
+func (p *Process) resetHealth() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.health = newHealthState()
+}
+
+// StartedAt returns when the current or last run started, or the zero time
+// if the process has never run.
+func (p *Process) StartedAt() time.Time {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.startedAt == nil {
+		return time.Time{}
+	}
+	return *p.startedAt
+}
+
+// setPlanned records the next planned stop or restart of the run that
+// closes done. It reports false if that run has already ended.
+func (p *Process) setPlanned(done <-chan struct{}, planned *plannedAction) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.doneCh == nil || (<-chan struct{})(p.doneCh) != done || p.state != model.StateRunning {
+		return false
+	}
+	p.planned = planned
+	return true
+}
+
+// PID returns the PID of the main process, or 0 if it is not running.
+func (p *Process) PID() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.pid
+}
+
+// recordUsage stores a resource usage sample of the process tree and adds
+// it to the service's usage history.
+func (p *Process) recordUsage(tree []procStat, now time.Time, resolution, retention time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.usage.record(tree, now)
+	p.history.add(model.MetricPoint{
+		Timestamp: now,
+		CPU:       p.usage.cpu,
+		Memory:    p.usage.memory,
+		Threads:   p.usage.threads,
+		FDs:       p.usage.fds,
+	}, resolution, retention)
+}
+
+// checkThresholds applies the watchdog limits to the latest usage sample.
+func (p *Process) checkThresholds(wd *config.WatchdogConfig, now time.Time) (thresholdBreach, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.state != model.StateRunning {
+		return thresholdBreach{}, false
+	}
+	return p.watchdog.check(wd, &p.usage, now)
+}
+
+// metrics returns the service's usage history, see metricHistory.query.
+func (p *Process) metrics(since time.Time, step time.Duration) []model.MetricPoint {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.history.query(since, step)
+}
+
+// Health returns the current health status of the process.
+func (p *Process) Health() model.HealthStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.health.status
+}
+
 // IncrementRestartCount increments the restart counter.
 func (p *Process) IncrementRestartCount() {
 	p.mu.Lock()
@@ -253,6 +761,28 @@ func (p *Process) ResetRestartCount() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	p.restartCount = 0
+	p.saveRunState()
+}
+
+// setBackoff moves the process into the backoff state until the monitor
+// restarts it at next. The returned channel is closed if the pending
+// restart is cancelled.
+func (p *Process) setBackoff(next time.Time) <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.state = model.StateBackoff
+	p.nextRestart = &next
+	p.cancelCh = make(chan struct{})
+	return p.cancelCh
+}
+
+// cancelRestart cancels a pending automatic restart. The caller must hold p.mu.
+func (p *Process) cancelRestart() {
+	if p.cancelCh != nil {
+		close(p.cancelCh)
+		p.cancelCh = nil
+	}
+	p.nextRestart = nil
 }
 
 // RestartCount returns the current restart count.
@@ -262,6 +792,14 @@ func (p *Process) RestartCount() int {
 	return p.restartCount
 }
 
+// setCrashLoop marks the service as having exhausted its restart attempts.
+func (p *Process) setCrashLoop(errMsg string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.state = model.StateCrashLoop
+	p.lastError = errMsg
+}
+
 func (p *Process) setFailed(errMsg string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -0,0 +1,108 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// procStat holds the fields of /proc/<pid>/stat used by the manager.
+type procStat struct {
+	pid       int
+	state     byte // R, S, D, Z, ...
+	ppid      int
+	pgrp      int
+	session   int
+	cpuTicks  uint64 // utime + stime, in clock ticks
+	threads   int
+	nice      int
+	startTime uint64 // clock ticks after boot
+	rss       uint64 // bytes
+	fds       int    // open file descriptors, filled in on demand by openFDs
+}
+
+// clockTicks is the kernel's USER_HZ, the unit of the CPU times in
+// /proc/<pid>/stat. It is 100 on all mainstream Linux architectures.
+const clockTicks = 100
+
+// listProcs returns the stat entries of all processes on the system.
+func listProcs() ([]procStat, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	var procs []procStat
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		ps, err := readProcStat(pid)
+		if err != nil {
+			continue // process exited in the meantime
+		}
+		procs = append(procs, ps)
+	}
+	return procs, nil
+}
+
+// openFDs returns the number of open file descriptors of a process, or 0
+// if they cannot be read.
+func openFDs(pid int) int {
+	entries, err := os.ReadDir("/proc/" + strconv.Itoa(pid) + "/fd")
+	if err != nil {
+		return 0
+	}
+	return len(entries)
+}
+
+// procStartTime returns the start time of a process in clock ticks after
+// boot. Together with the PID it identifies a process uniquely. Zombie
+// processes have exited and are reported as an error.
+func procStartTime(pid int) (uint64, error) {
+	ps, err := readProcStat(pid)
+	if err != nil {
+		return 0, err
+	}
+	if ps.state == 'Z' {
+		return 0, fmt.Errorf("process %d has exited", pid)
+	}
+	return ps.startTime, nil
+}
+
+// readProcStat parses /proc/<pid>/stat.
+func readProcStat(pid int) (procStat, error) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+	if err != nil {
+		return procStat{}, err
+	}
+
+	// The command name may contain spaces and parentheses, so the fields
+	// are parsed from the last closing parenthesis onwards.
+	s := string(data)
+	end := strings.LastIndexByte(s, ')')
+	if end < 0 {
+		return procStat{}, fmt.Errorf("malformed stat for PID %d", pid)
+	}
+	fields := strings.Fields(s[end+1:])
+	if len(fields) < 22 {
+		return procStat{}, fmt.Errorf("malformed stat for PID %d", pid)
+	}
+
+	// fields[0] is the state; fields[1:] start at ppid (field 4 in proc(5))
+	ps := procStat{pid: pid, state: fields[0][0]}
+	ps.ppid, _ = strconv.Atoi(fields[1])
+	ps.pgrp, _ = strconv.Atoi(fields[2])
+	ps.session, _ = strconv.Atoi(fields[3])
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	ps.cpuTicks = utime + stime
+	ps.nice, _ = strconv.Atoi(fields[16])
+	ps.threads, _ = strconv.Atoi(fields[17])
+	ps.startTime, _ = strconv.ParseUint(fields[19], 10, 64)
+	rssPages, _ := strconv.ParseUint(fields[21], 10, 64)
+	ps.rss = rssPages * uint64(os.Getpagesize())
+	return ps, nil
+}
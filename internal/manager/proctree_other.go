@@ -0,0 +1,38 @@
+//go:build !linux
+
+package manager
+
+import "errors"
+
+// procStat holds the per-process information used by the manager.
+type procStat struct {
+	pid       int
+	state     byte
+	ppid      int
+	pgrp      int
+	session   int
+	cpuTicks  uint64
+	threads   int
+	nice      int
+	startTime uint64
+	rss       uint64
+	fds       int // open file descriptors, filled in on demand by openFDs
+}
+
+// clockTicks is the unit of procStat.cpuTicks per second.
+const clockTicks = 100
+
+// listProcs is only implemented on Linux.
+func listProcs() ([]procStat, error) {
+	return nil, errors.New("process listing is not supported on this platform")
+}
+
+// procStartTime is only implemented on Linux.
+func procStartTime(pid int) (uint64, error) {
+	return 0, errors.New("process start times are not supported on this platform")
+}
+
+// openFDs is only implemented on Linux.
+func openFDs(pid int) int {
+	return 0
+}
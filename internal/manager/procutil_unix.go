@@ -0,0 +1,108 @@
+//go:build !windows
+
+package manager
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/BAIGUANGMEI/goser/internal/config"
+)
+
+var signals = map[string]syscall.Signal{
+	"SIGTERM": syscall.SIGTERM,
+	"SIGINT":  syscall.SIGINT,
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+	"SIGKILL": syscall.SIGKILL,
+}
+
+// procGroup is the process tree of a service. Services run in their own
+// session, so the session and process group IDs equal the main PID.
+type procGroup struct {
+	pid int
+}
+
+// setProcAttr makes the command start in a new session and process group.
+func setProcAttr(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
+
+// setCredential makes the command run as the given user and groups. It is a
+// no-op unless the daemon runs as root; configuration validation ensures an
+// unprivileged daemon is only asked to keep its own user.
+func setCredential(cmd *exec.Cmd, cred *config.Credentials) {
+	if os.Geteuid() != 0 {
+		return
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{
+		Uid:    cred.UID,
+		Gid:    cred.GID,
+		Groups: cred.Groups,
+	}
+}
+
+// newProcGroup returns the process tree rooted at a started process.
+func newProcGroup(pid int) *procGroup {
+	return &procGroup{pid: pid}
+}
+
+// signal delivers the named signal to the main process, or to the whole
+// process group when kill_mode is "group".
+func (g *procGroup) signal(name, killMode string) error {
+	sig, ok := signals[name]
+	if !ok {
+		return fmt.Errorf("unsupported signal %s", name)
+	}
+	if killMode == "group" {
+		return syscall.Kill(-g.pid, sig)
+	}
+	return syscall.Kill(g.pid, sig)
+}
+
+// kill forcefully terminates the main process, or the whole process group
+// unless kill_mode is "process".
+func (g *procGroup) kill(killMode string) error {
+	if killMode == "process" {
+		return syscall.Kill(g.pid, syscall.SIGKILL)
+	}
+	return syscall.Kill(-g.pid, syscall.SIGKILL)
+}
+
+// sweep kills every process left in the service's process group or session
+// after the main process has exited.
+func (g *procGroup) sweep() {
+	_ = syscall.Kill(-g.pid, syscall.SIGKILL)
+
+	// Descendants may have moved to another process group within the session
+	procs, err := listProcs()
+	if err != nil {
+		return
+	}
+	for _, ps := range procs {
+		if ps.session == g.pid && ps.pid != g.pid {
+			_ = syscall.Kill(ps.pid, syscall.SIGKILL)
+		}
+	}
+}
+
+// release frees resources held for the process tree.
+func (g *procGroup) release() {}
+
+// exitSignal returns the name of the signal that terminated a process,
+// or an empty string if it exited normally.
+func exitSignal(ps *os.ProcessState) string {
+	if ws, ok := ps.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return unix.SignalName(ws.Signal())
+	}
+	return ""
+}
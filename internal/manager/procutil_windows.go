@@ -0,0 +1,102 @@
+//go:build windows
+
+package manager
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/BAIGUANGMEI/goser/internal/config"
+	"github.com/BAIGUANGMEI/goser/internal/logger"
+)
+
+// procGroup is the process tree of a service, tracked with a Job Object
+// so that child processes can be terminated together with the service.
+type procGroup struct {
+	pid int
+	job windows.Handle
+}
+
+// setProcAttr configures how the command is started.
+func setProcAttr(cmd *exec.Cmd) {}
+
+// setCredential is not supported on Windows; configuration validation
+// rejects services with a user or group.
+func setCredential(cmd *exec.Cmd, cred *config.Credentials) {}
+
+// setPreExec is a no-op on Windows, which has no umask; resource limits
+// are not supported.
+func setPreExec(cmd *exec.Cmd, cfg *config.ServiceConfig) (*preExec, error) {
+	return nil, nil
+}
+
+// newProcGroup assigns a started process to a new Job Object. Processes it
+// creates afterwards are added to the job automatically.
+func newProcGroup(pid int) *procGroup {
+	g := &procGroup{pid: pid}
+
+	job, err := windows.CreateJobObject(nil, nil)
+	if err != nil {
+		logger.Get().Warnf("create job object for PID %d: %v", pid, err)
+		return g
+	}
+	h, err := windows.OpenProcess(windows.PROCESS_SET_QUOTA|windows.PROCESS_TERMINATE, false, uint32(pid))
+	if err != nil {
+		logger.Get().Warnf("open process %d: %v", pid, err)
+		_ = windows.CloseHandle(job)
+		return g
+	}
+	defer windows.CloseHandle(h)
+
+	if err := windows.AssignProcessToJobObject(job, h); err != nil {
+		logger.Get().Warnf("assign PID %d to job object: %v", pid, err)
+		_ = windows.CloseHandle(job)
+		return g
+	}
+	g.job = job
+	return g
+}
+
+// signal delivers the named signal. Windows has no POSIX signals, so
+// services without a stop_command are killed directly.
+func (g *procGroup) signal(name, killMode string) error {
+	return errors.New("signals are not supported on windows")
+}
+
+// kill forcefully terminates the main process, or the whole job unless
+// kill_mode is "process".
+func (g *procGroup) kill(killMode string) error {
+	if killMode != "process" && g.job != 0 {
+		return windows.TerminateJobObject(g.job, 1)
+	}
+	proc, err := os.FindProcess(g.pid)
+	if err != nil {
+		return err
+	}
+	return proc.Kill()
+}
+
+// sweep terminates every process left in the job after the main process
+// has exited.
+func (g *procGroup) sweep() {
+	if g.job != 0 {
+		_ = windows.TerminateJobObject(g.job, 1)
+	}
+}
+
+// release closes the Job Object handle.
+func (g *procGroup) release() {
+	if g.job != 0 {
+		_ = windows.CloseHandle(g.job)
+		g.job = 0
+	}
+}
+
+// exitSignal returns an empty string; processes are not terminated by
+// signals on Windows.
+func exitSignal(ps *os.ProcessState) string {
+	return ""
+}
@@ -0,0 +1,98 @@
+package manager
+
+import (
+	"time"
+
+	"github.com/BAIGUANGMEI/goser/internal/config"
+	"github.com/BAIGUANGMEI/goser/internal/logger"
+	"github.com/BAIGUANGMEI/goser/internal/model"
+)
+
+// watchServices periodically applies changes made to the service files on
+// disk until the manager stops.
+func (m *Manager) watchServices(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-m.stopCh:
+			return
+		}
+		if err := m.ReloadServices(); err != nil {
+			logger.Get().Warnf("config: reload failed: %v", err)
+		}
+	}
+}
+
+// ReloadServices applies the service files that were added, changed or
+// removed on disk. New services are registered without being started,
+// removed services are stopped and unregistered, and changed services are
+// updated as in UpdateService. A file that fails to load is reported with
+// a service.config_error event and leaves its service as it was.
+func (m *Manager) ReloadServices() error {
+	changes, err := m.loader.ReloadServices()
+	if err != nil {
+		return err
+	}
+	log := logger.Get()
+	m.reportFileErrors(changes.Errors)
+
+	// Remove dependents before the services they depend on
+	removed := make(map[string]*config.ServiceConfig, len(changes.Removed))
+	for _, svc := range changes.Removed {
+		removed[svc.Name] = svc
+	}
+	order := config.DependencyOrder(removed)
+	for i := len(order) - 1; i >= 0; i-- {
+		svc := removed[order[i]]
+		log.Infof("config: service %s removed", svc.Name)
+		m.unregisterService(svc)
+		m.emitEvent(model.Event{
+			Type:      model.EventServiceRemoved,
+			Service:   svc.Name,
+			Message:   "service removed (file deleted)",
+			Timestamp: time.Now(),
+		})
+	}
+
+	for _, svc := range changes.Added {
+		log.Infof("config: service %s added", svc.Name)
+		m.registerService(svc)
+		m.emitEvent(model.Event{
+			Type:      model.EventServiceAdded,
+			Service:   svc.Name,
+			Message:   "service added (file created)",
+			Timestamp: time.Now(),
+		})
+	}
+
+	for _, svc := range changes.Updated {
+		log.Infof("config: service %s updated", svc.Name)
+		m.applyConfig(changes.Previous[svc.Name], svc)
+		m.emitEvent(model.Event{
+			Type:      model.EventServiceUpdated,
+			Service:   svc.Name,
+			Message:   "service configuration updated (file changed)",
+			Timestamp: time.Now(),
+		})
+	}
+
+	return nil
+}
+
+// reportFileErrors logs the service files that failed to load and reports
+// each with a service.config_error event.
+func (m *Manager) reportFileErrors(errs []config.FileError) {
+	for _, fe := range errs {
+		logger.Get().Warnf("config: %v", fe)
+		m.emitEvent(model.Event{
+			Type:      model.EventServiceConfigError,
+			Service:   fe.Service(),
+			Message:   fe.Error(),
+			Data:      map[string]interface{}{"file": fe.Path},
+			Timestamp: time.Now(),
+		})
+	}
+}
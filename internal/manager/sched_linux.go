@@ -0,0 +1,79 @@
+package manager
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/BAIGUANGMEI/goser/internal/config"
+	"github.com/BAIGUANGMEI/goser/internal/model"
+)
+
+// I/O priority constants from linux/ioprio.h.
+const (
+	ioprioWhoProcess = 1
+	ioprioClassShift = 13
+)
+
+var ioprioClasses = []string{"none", "realtime", "best-effort", "idle"}
+
+// applyScheduling sets the nice value, I/O priority and OOM score
+// adjustment of a started process.
+func applyScheduling(pid int, cfg *config.ServiceConfig) error {
+	if cfg.Nice != nil {
+		if err := unix.Setpriority(unix.PRIO_PROCESS, pid, *cfg.Nice); err != nil {
+			return fmt.Errorf("set nice: %w", err)
+		}
+	}
+	if p := cfg.IOPrio; p != nil {
+		class := 0
+		for i, name := range ioprioClasses {
+			if name == p.Class {
+				class = i
+			}
+		}
+		prio := class<<ioprioClassShift | p.Level
+		if _, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(pid), uintptr(prio)); errno != 0 {
+			return fmt.Errorf("set ioprio: %w", errno)
+		}
+	}
+	if cfg.OOMScoreAdj != nil {
+		path := "/proc/" + strconv.Itoa(pid) + "/oom_score_adj"
+		if err := os.WriteFile(path, []byte(strconv.Itoa(*cfg.OOMScoreAdj)), 0); err != nil {
+			return fmt.Errorf("set oom_score_adj: %w", err)
+		}
+	}
+	return nil
+}
+
+// readScheduling returns the scheduling settings in effect for a process.
+func readScheduling(pid int) *model.SchedulingInfo {
+	info := &model.SchedulingInfo{IOPrio: "none"}
+	if ps, err := readProcStat(pid); err == nil {
+		info.Nice = ps.nice
+	}
+	if v, _, errno := unix.Syscall(unix.SYS_IOPRIO_GET, ioprioWhoProcess, uintptr(pid), 0); errno == 0 {
+		class, level := int(v>>ioprioClassShift), int(v&0xff)
+		if class > 0 && class < len(ioprioClasses) {
+			info.IOPrio = ioprioClasses[class]
+			if class != 3 {
+				info.IOPrio += "/" + strconv.Itoa(level)
+			}
+		}
+	}
+	if data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/oom_score_adj"); err == nil {
+		info.OOMScoreAdj, _ = strconv.Atoi(strings.TrimSpace(string(data)))
+	}
+	if data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/status"); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if v, ok := strings.CutPrefix(line, "Umask:"); ok {
+				info.Umask = strings.TrimSpace(v)
+				break
+			}
+		}
+	}
+	return info
+}
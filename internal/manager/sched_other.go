@@ -0,0 +1,22 @@
+//go:build !linux
+
+package manager
+
+import (
+	"github.com/BAIGUANGMEI/goser/internal/config"
+	"github.com/BAIGUANGMEI/goser/internal/logger"
+	"github.com/BAIGUANGMEI/goser/internal/model"
+)
+
+// applyScheduling is only implemented on Linux; the settings are ignored.
+func applyScheduling(pid int, cfg *config.ServiceConfig) error {
+	if cfg.Nice != nil || cfg.IOPrio != nil || cfg.OOMScoreAdj != nil {
+		logger.Get().Warnf("nice, ioprio and oom_score_adj are not supported on this platform, ignoring them for %s", cfg.Name)
+	}
+	return nil
+}
+
+// readScheduling is only implemented on Linux.
+func readScheduling(pid int) *model.SchedulingInfo {
+	return nil
+}
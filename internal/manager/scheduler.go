@@ -0,0 +1,132 @@
+package manager
+
+import (
+	"time"
+
+	"github.com/BAIGUANGMEI/goser/internal/logger"
+	"github.com/BAIGUANGMEI/goser/internal/model"
+)
+
+const (
+	// schedulePollInterval is how often the scheduler checks for due runs.
+	schedulePollInterval = time.Second
+	// maxJobRuns is the number of recent runs kept per scheduled service.
+	maxJobRuns = 20
+)
+
+// runScheduler starts scheduled services when their runs are due.
+func (m *Manager) runScheduler() {
+	ticker := time.NewTicker(schedulePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			m.mu.RLock()
+			procs := make([]*Process, 0, len(m.processes))
+			for _, p := range m.processes {
+				procs = append(procs, p)
+			}
+			m.mu.RUnlock()
+
+			for _, proc := range procs {
+				if proc.runDue(now) {
+					m.triggerRun(proc)
+				}
+			}
+		case <-m.stopCh:
+			return
+		}
+	}
+}
+
+// triggerRun starts a scheduled service. If the previous run is still
+// active, the run is skipped or queued according to concurrency_policy.
+func (m *Manager) triggerRun(proc *Process) {
+	log := logger.Get()
+	cfg := proc.Config()
+
+	if state := proc.State(); isActive(state) || state == model.StateStopping {
+		if cfg.ConcurrencyPolicy == "queue" {
+			log.Infof("scheduler: %s is still %s, queueing run", cfg.Name, state)
+			proc.queueRun()
+			return
+		}
+		log.Infof("scheduler: %s is still %s, skipping run", cfg.Name, state)
+		m.emitEvent(model.Event{
+			Type:      model.EventServiceRunSkipped,
+			Service:   cfg.Name,
+			Message:   "scheduled run skipped, previous run is still " + string(state),
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	log.Infof("scheduler: starting scheduled run of %s", cfg.Name)
+	if err := m.StartService(cfg.Name, model.StartOptions{WithDependencies: true, Transient: true}); err != nil {
+		log.Errorf("scheduler: failed to start %s: %v", cfg.Name, err)
+		m.emitEvent(model.Event{
+			Type:      model.EventServiceFailed,
+			Service:   cfg.Name,
+			Message:   "scheduled run failed to start: " + err.Error(),
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+// runDue reports whether a run of a scheduled service is due: its next
+// scheduled time has come, or a queued run can start now that the previous
+// one has finished.
+func (p *Process) runDue(now time.Time) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	cron := p.config.CronSchedule()
+	if cron == nil {
+		p.nextRun = nil
+		p.runQueued = false
+		return false
+	}
+	if p.runQueued && !isActive(p.state) && p.state != model.StateStopping {
+		p.runQueued = false
+		return true
+	}
+	if p.nextRun == nil {
+		next := cron.Next(now)
+		p.nextRun = &next
+		return false
+	}
+	// A zero time means the schedule never fires again
+	if p.nextRun.IsZero() || now.Before(*p.nextRun) {
+		return false
+	}
+	next := cron.Next(now)
+	p.nextRun = &next
+	return true
+}
+
+// queueRun queues a run to start once the current one has finished.
+func (p *Process) queueRun() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.runQueued = true
+}
+
+// recordRun adds the run that just finished to the run history. The caller
+// must hold p.mu.
+func (p *Process) recordRun(finishedAt time.Time) {
+	if p.startedAt == nil {
+		return
+	}
+	p.runs = append(p.runs, model.JobRun{
+		StartedAt:  *p.startedAt,
+		FinishedAt: finishedAt,
+		Duration:   finishedAt.Sub(*p.startedAt).Round(time.Millisecond).String(),
+		ExitCode:   p.exitCode,
+		ExitSignal: p.exitSignal,
+		Error:      p.lastError,
+	})
+	if len(p.runs) > maxJobRuns {
+		p.runs = p.runs[len(p.runs)-maxJobRuns:]
+	}
+}
@@ -0,0 +1,73 @@
+package manager
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runState is the runtime state of a running service, persisted so that a
+// restarted daemon can find the processes it left behind.
+type runState struct {
+	Name         string    `json:"name"`
+	PID          int       `json:"pid"`
+	StartTime    uint64    `json:"start_time"` // process start time, guards against PID reuse
+	StartedAt    time.Time `json:"started_at"`
+	RestartCount int       `json:"restart_count"`
+}
+
+func runStatePath(dir, name string) string {
+	return filepath.Join(dir, name+".json")
+}
+
+// saveRunState writes the state file of a service atomically.
+func saveRunState(dir string, st runState) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := runStatePath(dir, st.Name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// removeRunState deletes the state file of a service.
+func removeRunState(dir, name string) {
+	_ = os.Remove(runStatePath(dir, name))
+}
+
+// loadRunStates reads all state files in dir. Unreadable files are skipped.
+func loadRunStates(dir string) []runState {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var states []runState
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			continue
+		}
+		var st runState
+		if err := json.Unmarshal(data, &st); err != nil || st.Name == "" || st.PID <= 0 {
+			continue
+		}
+		states = append(states, st)
+	}
+	return states
+}
+
+// alive reports whether the process recorded in the state is still running,
+// and is the same process rather than a new one that reused its PID.
+func (st runState) alive() bool {
+	startTime, err := procStartTime(st.PID)
+	return err == nil && startTime == st.StartTime
+}
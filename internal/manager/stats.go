@@ -0,0 +1,137 @@
+package manager
+
+import (
+	"time"
+
+	"github.com/BAIGUANGMEI/goser/internal/logger"
+	"github.com/BAIGUANGMEI/goser/internal/model"
+)
+
+// resourceUsage is the sampled resource usage of a service's process tree.
+type resourceUsage struct {
+	cpu       float64 // percent of one CPU
+	memory    uint64  // resident set size in bytes
+	threads   int
+	fds       int
+	ticks     map[int]uint64
+	sampledAt time.Time
+}
+
+// record updates the usage from the current state of the process tree.
+// The CPU percentage is computed from the CPU time used since the previous
+// sample, so it is only available from the second sample on.
+func (u *resourceUsage) record(tree []procStat, now time.Time) {
+	ticks := make(map[int]uint64, len(tree))
+	var used, memory uint64
+	var threads, fds int
+	for _, ps := range tree {
+		ticks[ps.pid] = ps.cpuTicks
+		memory += ps.rss
+		threads += ps.threads
+		fds += ps.fds
+		if prev, ok := u.ticks[ps.pid]; ok && ps.cpuTicks >= prev {
+			used += ps.cpuTicks - prev
+		} else if !u.sampledAt.IsZero() {
+			// Started since the previous sample
+			used += ps.cpuTicks
+		}
+	}
+
+	if !u.sampledAt.IsZero() {
+		if elapsed := now.Sub(u.sampledAt).Seconds(); elapsed > 0 {
+			u.cpu = float64(used) / clockTicks / elapsed * 100
+		}
+	}
+	u.memory = memory
+	u.threads = threads
+	u.fds = fds
+	u.ticks = ticks
+	u.sampledAt = now
+}
+
+// sampleUsage periodically samples the CPU and memory usage of all running
+// services and adds it to their history until the manager is stopped.
+func (m *Manager) sampleUsage(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-m.stopCh:
+			return
+		}
+
+		procs, err := listProcs()
+		if err != nil {
+			logger.Get().Warnf("stats: resource sampling disabled: %v", err)
+			return
+		}
+		now := time.Now()
+
+		m.mu.RLock()
+		running := make([]*Process, 0, len(m.processes))
+		for _, p := range m.processes {
+			if p.State() == model.StateRunning {
+				running = append(running, p)
+			}
+		}
+		m.mu.RUnlock()
+
+		for _, p := range running {
+			pid := p.PID()
+			if pid <= 0 {
+				continue
+			}
+			tree := processTree(procs, pid)
+			for i := range tree {
+				tree[i].fds = openFDs(tree[i].pid)
+			}
+			p.recordUsage(tree, now, m.metricsResolution, m.metricsRetention)
+			m.checkWatchdog(p, now)
+		}
+	}
+}
+
+// processTree returns the processes belonging to the service whose main
+// process is pid: everything in its session plus any descendants that
+// have moved to a session of their own.
+func processTree(procs []procStat, pid int) []procStat {
+	children := make(map[int][]int)
+	byPID := make(map[int]procStat, len(procs))
+	for _, ps := range procs {
+		children[ps.ppid] = append(children[ps.ppid], ps.pid)
+		byPID[ps.pid] = ps
+	}
+
+	seen := make(map[int]bool)
+	var tree []procStat
+	add := func(ps procStat) {
+		if !seen[ps.pid] {
+			seen[ps.pid] = true
+			tree = append(tree, ps)
+		}
+	}
+	for _, ps := range procs {
+		if ps.session == pid {
+			add(ps)
+		}
+	}
+
+	visited := map[int]bool{pid: true}
+	queue := []int{pid}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if ps, ok := byPID[cur]; ok {
+			add(ps)
+		}
+		for _, child := range children[cur] {
+			if !visited[child] {
+				visited[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+	return tree
+}
@@ -0,0 +1,110 @@
+package manager
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BAIGUANGMEI/goser/internal/config"
+	"github.com/BAIGUANGMEI/goser/internal/logger"
+	"github.com/BAIGUANGMEI/goser/internal/model"
+)
+
+// restartReasonThreshold is reported when the watchdog restarts a service.
+const restartReasonThreshold = "threshold"
+
+// watchdogState tracks since when each watchdog limit has been exceeded.
+type watchdogState struct {
+	memorySince time.Time
+	cpuSince    time.Time
+}
+
+// thresholdBreach describes a watchdog limit that has been exceeded for
+// longer than the configured period.
+type thresholdBreach struct {
+	Limit     string        `json:"limit"` // memory | cpu
+	Value     float64       `json:"value"`
+	Threshold float64       `json:"threshold"`
+	Duration  time.Duration `json:"duration"`
+}
+
+func (b thresholdBreach) String() string {
+	if b.Limit == "memory" {
+		return fmt.Sprintf("memory %.1f MiB above %.1f MiB for %s",
+			b.Value/(1<<20), b.Threshold/(1<<20), b.Duration.Round(time.Second))
+	}
+	return fmt.Sprintf("cpu %.1f%% above %.1f%% for %s", b.Value, b.Threshold, b.Duration.Round(time.Second))
+}
+
+// check compares the latest usage sample against the watchdog limits and
+// returns a breach once a limit has been exceeded for the whole period.
+// The period starts over after a breach is reported.
+func (w *watchdogState) check(wd *config.WatchdogConfig, usage *resourceUsage, now time.Time) (thresholdBreach, bool) {
+	track := func(since *time.Time, over bool) (time.Duration, bool) {
+		if !over {
+			*since = time.Time{}
+			return 0, false
+		}
+		if since.IsZero() {
+			*since = now
+		}
+		d := now.Sub(*since)
+		if d < wd.For {
+			return d, false
+		}
+		*since = now
+		return d, true
+	}
+
+	if limit := wd.MemoryLimit(); limit > 0 {
+		if d, tripped := track(&w.memorySince, usage.memory > limit); tripped {
+			return thresholdBreach{Limit: "memory", Value: float64(usage.memory), Threshold: float64(limit), Duration: d}, true
+		}
+	}
+	if wd.MaxCPUPercent > 0 {
+		if d, tripped := track(&w.cpuSince, usage.cpu > wd.MaxCPUPercent); tripped {
+			return thresholdBreach{Limit: "cpu", Value: usage.cpu, Threshold: wd.MaxCPUPercent, Duration: d}, true
+		}
+	}
+	return thresholdBreach{}, false
+}
+
+// checkWatchdog applies the service's watchdog to its latest usage sample.
+func (m *Manager) checkWatchdog(proc *Process, now time.Time) {
+	wd := proc.Config().Watchdog
+	if wd == nil {
+		return
+	}
+	breach, tripped := proc.checkThresholds(wd, now)
+	if !tripped {
+		return
+	}
+
+	log := logger.Get()
+	name := proc.Config().Name
+	log.Warnf("watchdog: %s exceeded its threshold: %s (action: %s)", name, breach, wd.Action)
+	m.emitEvent(model.Event{
+		Type:      model.EventServiceThresholdExceeded,
+		Service:   name,
+		Message:   breach.String(),
+		Data:      breach,
+		Timestamp: now,
+	})
+
+	// Stopping blocks for up to stop_timeout, so it must not hold up the sampler.
+	switch wd.Action {
+	case "restart":
+		go func() {
+			if err := proc.Terminate(restartReasonThreshold); err != nil {
+				log.Errorf("watchdog: failed to terminate %s: %v", name, err)
+			}
+		}()
+	case "stop":
+		go func() {
+			if err := proc.Stop(); err != nil {
+				log.Errorf("watchdog: failed to stop %s: %v", name, err)
+				return
+			}
+			m.emitStopped(proc, "service stopped because it exceeded its "+breach.Limit+" threshold")
+		}()
+	}
+}
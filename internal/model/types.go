@@ -1,65 +1,305 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
 // ServiceState represents the lifecycle state of a managed service.
 type ServiceState string
 
 const (
-	StateStopped  ServiceState = "stopped"
-	StateStarting ServiceState = "starting"
-	StateRunning  ServiceState = "running"
-	StateStopping ServiceState = "stopping"
-	StateFailed   ServiceState = "failed"
+	StateStopped   ServiceState = "stopped"
+	StateStarting  ServiceState = "starting"
+	StateWaiting   ServiceState = "waiting"
+	StateRunning   ServiceState = "running"
+	StateStopping  ServiceState = "stopping"
+	StateFailed    ServiceState = "failed"
+	StateBackoff   ServiceState = "backoff"    // waiting for an automatic restart
+	StateCrashLoop ServiceState = "crash-loop" // gave up after max_restarts
+	StateCompleted ServiceState = "completed"  // a oneshot service that exited successfully
+)
+
+// DesiredState is the state a service is restored to when the daemon starts.
+type DesiredState string
+
+const (
+	DesiredStarted DesiredState = "started"
+	DesiredStopped DesiredState = "stopped"
 )
 
 // ServiceInfo contains runtime information about a managed service.
 type ServiceInfo struct {
-	Name         string            `json:"name"`
-	State        ServiceState      `json:"state"`
-	PID          int               `json:"pid,omitempty"`
-	Command      string            `json:"command"`
-	Args         []string          `json:"args,omitempty"`
-	WorkingDir   string            `json:"working_dir,omitempty"`
-	Env          map[string]string `json:"env,omitempty"`
-	AutoStart    bool              `json:"auto_start"`
-	AutoRestart  bool              `json:"auto_restart"`
-	RestartCount int               `json:"restart_count"`
-	StartedAt    *time.Time        `json:"started_at,omitempty"`
-	StoppedAt    *time.Time        `json:"stopped_at,omitempty"`
-	Uptime       string            `json:"uptime,omitempty"`
-	CPU          float64           `json:"cpu,omitempty"`
-	Memory       uint64            `json:"memory,omitempty"`
-	ExitCode     *int              `json:"exit_code,omitempty"`
-	Error        string            `json:"error,omitempty"`
+	Name          string            `json:"name"`
+	State         ServiceState      `json:"state"`
+	DesiredState  DesiredState      `json:"desired_state"`
+	PID           int               `json:"pid,omitempty"`
+	Type          string            `json:"type,omitempty"`
+	InstanceOf    string            `json:"instance_of,omitempty"` // multi-instance service this process is an instance of
+	Command       string            `json:"command"`
+	Args          []string          `json:"args,omitempty"`
+	WorkingDir    string            `json:"working_dir,omitempty"`
+	Env           map[string]string `json:"env,omitempty"`
+	User          string            `json:"user,omitempty"`
+	Group         string            `json:"group,omitempty"`
+	AutoStart     bool              `json:"auto_start"`
+	AutoRestart   bool              `json:"auto_restart"`
+	Restart       string            `json:"restart,omitempty"`
+	RestartCount  int               `json:"restart_count"`
+	MaxRestarts   int               `json:"max_restarts,omitempty"`
+	NextRestartAt *time.Time        `json:"next_restart_at,omitempty"`
+	NextPlannedAt *time.Time        `json:"next_planned_at,omitempty"` // next stop or restart by max_runtime, restart_every or restart_at
+	PlannedAction string            `json:"planned_action,omitempty"`
+	StartedAt     *time.Time        `json:"started_at,omitempty"`
+	StoppedAt     *time.Time        `json:"stopped_at,omitempty"`
+	Uptime        string            `json:"uptime,omitempty"`
+	CPU           float64           `json:"cpu,omitempty"`
+	Memory        uint64            `json:"memory,omitempty"`
+	ExitCode      *int              `json:"exit_code,omitempty"`
+	ExitSignal    string            `json:"exit_signal,omitempty"`
+	Error         string            `json:"error,omitempty"`
+	Health        *HealthInfo       `json:"health,omitempty"`
+	Limits        []ResourceLimit   `json:"limits,omitempty"`
+	Scheduling    *SchedulingInfo   `json:"scheduling,omitempty"`
+	Adopted       bool              `json:"adopted,omitempty"` // process was started by a previous daemon instance
+	Schedule      string            `json:"schedule,omitempty"`
+	NextRunAt     *time.Time        `json:"next_run_at,omitempty"`
+	Runs          []JobRun          `json:"runs,omitempty"` // recent runs of a scheduled service, oldest first
+
+	ConfigRevision int  `json:"config_revision"`           // incremented by each configuration update
+	RunRevision    int  `json:"run_revision,omitempty"`    // revision the running process was started or reloaded with
+	RestartPending bool `json:"restart_pending,omitempty"` // config changed, restart pending
+}
+
+// ResourceLimit is an OS resource limit in effect for a service's process.
+type ResourceLimit struct {
+	Name string `json:"name"` // nofile, nproc, core, as, data or cpu
+	Soft int64  `json:"soft"` // -1 means unlimited
+	Hard int64  `json:"hard"`
+}
+
+// JobRun records a single run of a scheduled service.
+type JobRun struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Duration   string    `json:"duration"`
+	ExitCode   *int      `json:"exit_code"`
+	ExitSignal string    `json:"exit_signal,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// SchedulingInfo contains the scheduling settings in effect for a
+// service's process.
+type SchedulingInfo struct {
+	Nice        int    `json:"nice"`
+	IOPrio      string `json:"ioprio"` // class/level, e.g. best-effort/7, or idle or none
+	OOMScoreAdj int    `json:"oom_score_adj"`
+	Umask       string `json:"umask,omitempty"`
+}
+
+// HealthStatus represents the outcome of a service's health checks.
+type HealthStatus string
+
+const (
+	HealthUnknown   HealthStatus = "unknown"
+	HealthHealthy   HealthStatus = "healthy"
+	HealthUnhealthy HealthStatus = "unhealthy"
+)
+
+// HealthInfo contains the current health check state of a service.
+type HealthInfo struct {
+	Status               HealthStatus   `json:"status"`
+	ConsecutiveFailures  int            `json:"consecutive_failures"`
+	ConsecutiveSuccesses int            `json:"consecutive_successes"`
+	LastOutput           string         `json:"last_output,omitempty"`
+	LastCheckAt          *time.Time     `json:"last_check_at,omitempty"`
+	Results              []HealthResult `json:"results,omitempty"`
+}
+
+// HealthResult records the outcome of a single health probe.
+type HealthResult struct {
+	Healthy   bool      `json:"healthy"`
+	Output    string    `json:"output,omitempty"`
+	Duration  string    `json:"duration"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// MetricPoint is a resource usage sample of a service's process tree.
+type MetricPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	CPU       float64   `json:"cpu"`    // percent of one CPU
+	Memory    uint64    `json:"memory"` // resident set size in bytes
+	Threads   int       `json:"threads"`
+	FDs       int       `json:"fds"`
+}
+
+// ServiceMetrics is the resource usage history of a service.
+type ServiceMetrics struct {
+	Service string        `json:"service"`
+	Step    string        `json:"step"`
+	Points  []MetricPoint `json:"points"`
 }
 
 // DaemonStatus contains the status of the daemon process.
 type DaemonStatus struct {
-	Running      bool      `json:"running"`
-	PID          int       `json:"pid"`
-	StartedAt    time.Time `json:"started_at"`
-	Uptime       string    `json:"uptime"`
-	ServiceCount int       `json:"service_count"`
-	RunningCount int       `json:"running_count"`
-	StoppedCount int       `json:"stopped_count"`
-	FailedCount  int       `json:"failed_count"`
+	Running        bool      `json:"running"`
+	PID            int       `json:"pid"`
+	StartedAt      time.Time `json:"started_at"`
+	Uptime         string    `json:"uptime"`
+	ServiceCount   int       `json:"service_count"`
+	RunningCount   int       `json:"running_count"`
+	StoppedCount   int       `json:"stopped_count"`
+	FailedCount    int       `json:"failed_count"`
+	BackoffCount   int       `json:"backoff_count"`
+	CrashLoopCount int       `json:"crash_loop_count"`
+}
+
+// DaemonReload is the result of reloading the global configuration. Each
+// list holds the keys of changed daemon settings.
+type DaemonReload struct {
+	Applied         []string          `json:"applied"`          // applied while the daemon runs
+	RestartRequired []string          `json:"restart_required"` // take effect when the daemon restarts
+	Failed          map[string]string `json:"failed,omitempty"` // could not be applied, with the error
+}
+
+// StartOptions controls how a service start request is handled.
+type StartOptions struct {
+	// WithDependencies starts any stopped dependencies first and waits
+	// for them to become ready.
+	WithDependencies bool `json:"with_dependencies"`
+	// Transient starts the service without recording it as the state to
+	// restore when the daemon restarts.
+	Transient bool `json:"transient"`
+}
+
+// DependencyError is returned when a service cannot be started because
+// some of its dependencies are not running.
+type DependencyError struct {
+	Service      string   `json:"service"`
+	Dependencies []string `json:"dependencies"`
+}
+
+func (e *DependencyError) Error() string {
+	return "service " + e.Service + " depends on services that are not running: " + strings.Join(e.Dependencies, ", ")
+}
+
+// StopOptions controls how a service stop or removal request is handled.
+type StopOptions struct {
+	// Cascade stops every service that depends on the service first.
+	Cascade bool `json:"cascade"`
+	// Transient stops the service without recording it as the state to
+	// restore when the daemon restarts.
+	Transient bool `json:"transient"`
+}
+
+// ScaleRequest sets the number of instances of a multi-instance service.
+type ScaleRequest struct {
+	Instances int `json:"instances"`
+}
+
+// Apply modes of a service configuration update.
+const (
+	ApplyNone         = "none"          // running processes keep their configuration until restarted
+	ApplyRestart      = "restart"       // restart the running processes with a changed configuration
+	ApplyReloadSignal = "reload-signal" // send reload_signal to the running processes with a changed configuration
+)
+
+// UpdateOptions controls how a service configuration update is applied to
+// its running processes.
+type UpdateOptions struct {
+	Apply string `json:"apply"` // none (default) | restart | reload-signal
+}
+
+// DependentsError is returned when a service cannot be stopped because
+// other running services depend on it.
+type DependentsError struct {
+	Service    string   `json:"service"`
+	Dependents []string `json:"dependents"`
+}
+
+func (e *DependentsError) Error() string {
+	return "service " + e.Service + " is required by running services: " + strings.Join(e.Dependents, ", ") +
+		" (stop them first or use cascade)"
+}
+
+// DependencyGraph describes the dependencies between services.
+type DependencyGraph struct {
+	Nodes  []GraphNode `json:"nodes"`
+	Order  []string    `json:"order"` // boot order
+	Cycles [][]string  `json:"cycles,omitempty"`
+}
+
+// GraphNode is a service in the dependency graph, annotated with its state.
+type GraphNode struct {
+	Name      string       `json:"name"`
+	State     ServiceState `json:"state"`
+	DependsOn []string     `json:"depends_on,omitempty"`
+	Missing   []string     `json:"missing,omitempty"` // dependencies that are not configured
+}
+
+// DOT renders the graph in Graphviz DOT format. Edges point from a service
+// to its dependencies; missing dependencies are drawn dashed.
+func (g *DependencyGraph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph goser {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=rounded];\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %q [label=%q, color=%q];\n", n.Name, n.Name+"\n"+string(n.State), stateColor(n.State))
+	}
+	for _, n := range g.Nodes {
+		for _, dep := range n.DependsOn {
+			fmt.Fprintf(&b, "  %q -> %q;\n", n.Name, dep)
+		}
+		for _, dep := range n.Missing {
+			fmt.Fprintf(&b, "  %q [label=%q, style=dashed, color=red];\n", dep, dep+"\nmissing")
+			fmt.Fprintf(&b, "  %q -> %q [style=dashed, color=red];\n", n.Name, dep)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func stateColor(state ServiceState) string {
+	switch state {
+	case StateRunning:
+		return "green"
+	case StateFailed, StateCrashLoop:
+		return "red"
+	case StateStopped:
+		return "gray"
+	case StateCompleted:
+		return "blue"
+	default:
+		return "orange"
+	}
 }
 
 // EventType represents the type of a service event.
 type EventType string
 
 const (
-	EventServiceStarted   EventType = "service.started"
-	EventServiceStopped   EventType = "service.stopped"
-	EventServiceFailed    EventType = "service.failed"
-	EventServiceRestarted EventType = "service.restarted"
-	EventServiceAdded     EventType = "service.added"
-	EventServiceRemoved   EventType = "service.removed"
-	EventServiceUpdated   EventType = "service.updated"
-	EventServiceLog       EventType = "service.log"
-	EventDaemonStarted    EventType = "daemon.started"
-	EventDaemonStopping   EventType = "daemon.stopping"
+	EventServiceStarted           EventType = "service.started"
+	EventServiceStopped           EventType = "service.stopped"
+	EventServiceFailed            EventType = "service.failed"
+	EventServiceRestarted         EventType = "service.restarted"
+	EventServiceAdded             EventType = "service.added"
+	EventServiceRemoved           EventType = "service.removed"
+	EventServiceUpdated           EventType = "service.updated"
+	EventServiceLog               EventType = "service.log"
+	EventServiceHealthy           EventType = "service.healthy"
+	EventServiceUnhealthy         EventType = "service.unhealthy"
+	EventServiceThresholdExceeded EventType = "service.threshold_exceeded"
+	EventServiceAdopted           EventType = "service.adopted"
+	EventServiceRunSkipped        EventType = "service.run_skipped"
+	EventServiceCompleted         EventType = "service.completed"
+	EventServiceScaled            EventType = "service.scaled"
+	EventServiceConfigError       EventType = "service.config_error"
+	EventServiceReloaded          EventType = "service.reloaded"
+	EventDaemonStarted            EventType = "daemon.started"
+	EventDaemonStopping           EventType = "daemon.stopping"
+	EventDaemonReloaded           EventType = "daemon.reloaded"
 )
 
 // Event represents a real-time event from the daemon.
@@ -0,0 +1,213 @@
+// Package schedule parses cron expressions and computes their activation times.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule computes the activation times of a scheduled job.
+type Schedule interface {
+	// Next returns the first activation time after t, or the zero time if
+	// there is none within the next five years.
+	Next(t time.Time) time.Time
+}
+
+// shorthands maps the predefined schedules to cron expressions.
+var shorthands = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a schedule: a standard 5-field cron expression (minute, hour,
+// day of month, month, day of week), one of the shorthands @yearly,
+// @monthly, @weekly, @daily and @hourly, or "@every <duration>". Cron times
+// are interpreted in loc.
+func Parse(spec string, loc *time.Location) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration in %q: %w", spec, err)
+		}
+		if d < time.Second {
+			return nil, fmt.Errorf("@every interval must be at least 1s")
+		}
+		return every(d), nil
+	}
+	if expr, ok := shorthands[strings.ToLower(spec)]; ok {
+		spec = expr
+	} else if strings.HasPrefix(spec, "@") {
+		return nil, fmt.Errorf("unknown schedule %q", spec)
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields (minute hour day-of-month month day-of-week)", spec)
+	}
+	c := &cron{loc: loc}
+	var err error
+	if c.minute, err = parseField(fields[0], minutes); err != nil {
+		return nil, err
+	}
+	if c.hour, err = parseField(fields[1], hours); err != nil {
+		return nil, err
+	}
+	if c.dom, err = parseField(fields[2], daysOfMonth); err != nil {
+		return nil, err
+	}
+	if c.month, err = parseField(fields[3], months); err != nil {
+		return nil, err
+	}
+	if c.dow, err = parseField(fields[4], daysOfWeek); err != nil {
+		return nil, err
+	}
+	// Sunday may be written as 7
+	if c.dow.has(7) {
+		c.dow |= 1
+	}
+	c.domAny = fields[2] == "*" || fields[2] == "?"
+	c.dowAny = fields[4] == "*" || fields[4] == "?"
+	return c, nil
+}
+
+// every is a schedule that activates at a fixed interval.
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// bits is a set of values between 0 and 63.
+type bits uint64
+
+func (b bits) has(v int) bool {
+	return b&(1<<uint(v)) != 0
+}
+
+// cron is a schedule defined by a cron expression.
+type cron struct {
+	minute, hour, dom, month, dow bits
+	domAny, dowAny                bool
+	loc                           *time.Location
+}
+
+func (c *cron) Next(t time.Time) time.Time {
+	t = t.In(c.loc).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case !c.month.has(int(t.Month())):
+			t = advance(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, c.loc))
+		case !c.dayMatches(t):
+			t = advance(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, c.loc))
+		case !c.hour.has(t.Hour()):
+			t = advance(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, c.loc))
+		case !c.minute.has(t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches applies the usual cron rule for the two day fields: if both
+// are restricted, a day matching either of them matches.
+func (c *cron) dayMatches(t time.Time) bool {
+	dom := c.dom.has(t.Day())
+	dow := c.dow.has(int(t.Weekday()))
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// advance returns next, or one hour after t if a daylight saving time
+// transition made next fall before t.
+func advance(t, next time.Time) time.Time {
+	if next.After(t) {
+		return next
+	}
+	return t.Add(time.Hour)
+}
+
+// fieldRange describes the values allowed in a cron field.
+type fieldRange struct {
+	name     string
+	min, max int
+	names    []string // names for the values starting at min, if any
+}
+
+var (
+	minutes     = fieldRange{name: "minute", min: 0, max: 59}
+	hours       = fieldRange{name: "hour", min: 0, max: 23}
+	daysOfMonth = fieldRange{name: "day of month", min: 1, max: 31}
+	months      = fieldRange{name: "month", min: 1, max: 12,
+		names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	daysOfWeek = fieldRange{name: "day of week", min: 0, max: 7,
+		names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+// parseField parses a comma-separated list of values, ranges and steps.
+func parseField(field string, r fieldRange) (bits, error) {
+	var b bits
+	for _, part := range strings.Split(field, ",") {
+		lo, hi, step := r.min, r.max, 1
+
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", stepStr, r.name)
+			}
+			step = n
+		}
+
+		if rng != "*" && rng != "?" {
+			from, to, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = r.value(from); err != nil {
+				return 0, err
+			}
+			switch {
+			case isRange:
+				if hi, err = r.value(to); err != nil {
+					return 0, err
+				}
+			case !hasStep:
+				hi = lo
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q in %s field", rng, r.name)
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			b |= 1 << uint(v)
+		}
+	}
+	return b, nil
+}
+
+// value parses a single number or name of the field.
+func (r fieldRange) value(s string) (int, error) {
+	for i, name := range r.names {
+		if strings.EqualFold(s, name) {
+			return r.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < r.min || v > r.max {
+		return 0, fmt.Errorf("invalid %s %q (expected %d-%d)", r.name, s, r.min, r.max)
+	}
+	return v, nil
+}